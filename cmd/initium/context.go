@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/telemetry"
 )
 
 type loggerKey struct{}
@@ -12,3 +13,11 @@ type loggerKey struct{}
 func withLogger(ctx context.Context, log *logging.Logger) context.Context {
 	return context.WithValue(ctx, loggerKey{}, log)
 }
+
+type telemetryKey struct{}
+
+// withTelemetry returns a new context.Context that carries a telemetry
+// handle, so PersistentPostRunE can retrieve it to shut it down cleanly.
+func withTelemetry(ctx context.Context, tel *telemetry.Telemetry) context.Context {
+	return context.WithValue(ctx, telemetryKey{}, tel)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/kitstream/initium/internal/cmd"
 	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/telemetry"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -11,7 +12,13 @@ import (
 var version = "dev"
 
 func main() {
-	var jsonLogs bool
+	var (
+		jsonLogs           bool
+		otelEndpoint       string
+		otelProtocol       string
+		metricsListen      string
+		metricsPushGateway string
+	)
 	root := &cobra.Command{
 		Use:   "initium",
 		Short: "Swiss-army toolbox for Kubernetes initContainers",
@@ -22,13 +29,35 @@ arbitrary commands — all with safe defaults, structured logging,
 and security guardrails.`,
 		Version:       version,
 		SilenceErrors: true,
-		PersistentPreRun: func(c *cobra.Command, args []string) {
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
 			if l, ok := c.Context().Value(loggerKey{}).(*logging.Logger); ok {
 				l.SetJSON(jsonLogs)
 			}
+
+			tel, err := telemetry.New(telemetry.Config{
+				OTELEndpoint:   otelEndpoint,
+				OTELProtocol:   otelProtocol,
+				MetricsListen:  metricsListen,
+				PushGatewayURL: metricsPushGateway,
+			})
+			if err != nil {
+				return err
+			}
+			c.SetContext(withTelemetry(c.Context(), tel))
+			return nil
+		},
+		PersistentPostRunE: func(c *cobra.Command, args []string) error {
+			if tel, ok := c.Context().Value(telemetryKey{}).(*telemetry.Telemetry); ok {
+				return tel.Shutdown(context.Background())
+			}
+			return nil
 		},
 	}
 	root.PersistentFlags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	root.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "", "OTLP collector endpoint (host:port) for trace export; empty disables tracing")
+	root.PersistentFlags().StringVar(&otelProtocol, "otel-protocol", "grpc", "OTLP exporter protocol: grpc or http")
+	root.PersistentFlags().StringVar(&metricsListen, "metrics-listen", "", "Address to serve Prometheus metrics on, e.g. :9090; empty disables the listener")
+	root.PersistentFlags().StringVar(&metricsPushGateway, "metrics-pushgateway", "", "Prometheus PushGateway URL to push metrics to once before exit, instead of listening (recommended for short-lived initContainers)")
 	log := logging.Default()
 	ctx := withLogger(context.Background(), log)
 	root.SetContext(ctx)
@@ -38,6 +67,8 @@ and security guardrails.`,
 	root.AddCommand(cmd.NewRenderCmd(log))
 	root.AddCommand(cmd.NewFetchCmd(log))
 	root.AddCommand(cmd.NewExecCmd(log))
+	root.AddCommand(cmd.NewUserCmd(log))
+	root.AddCommand(cmd.NewAcmeCmd(log))
 	if err := root.Execute(); err != nil {
 		log.Error(err.Error())
 		os.Exit(1)
@@ -0,0 +1,478 @@
+// Package acme obtains and renews TLS certificates from an ACME CA (Let's
+// Encrypt by default) using HTTP-01 or DNS-01 validation, so a container can
+// start with a certificate on disk without a sidecar like cert-manager.
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme"
+
+	"github.com/kitstream/initium/internal/safety"
+)
+
+// LEDirectoryURL and LEStagingDirectoryURL are the well-known Let's Encrypt
+// ACME directory endpoints, used when --ca-directory is not set.
+const (
+	LEDirectoryURL        = "https://acme-v02.api.letsencrypt.org/directory"
+	LEStagingDirectoryURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+type Config struct {
+	Domains     []string
+	Workdir     string
+	AccountKey  string
+	CertOutput  string
+	KeyOutput   string
+	HTTPPort    int
+	DNSProvider string
+	RenewBefore time.Duration
+	Staging     bool
+	CADirectory string
+	EABKeyID    string
+	EABHMACEnv  string
+	MustStaple  bool
+
+	// RFC2136Nameserver, RFC2136TSIGKey, RFC2136TSIGSecretEnv, and
+	// RFC2136TSIGAlgorithm configure the "rfc2136" DNS-01 provider.
+	RFC2136Nameserver    string
+	RFC2136TSIGKey       string
+	RFC2136TSIGSecretEnv string
+	RFC2136TSIGAlgorithm string
+
+	// Route53HostedZoneID configures the "route53" DNS-01 provider; left
+	// empty, the zone is discovered from the domain via ListHostedZones.
+	Route53HostedZoneID string
+
+	// DNSPropagationTimeout bounds how long presentChallenge polls for a
+	// freshly published DNS-01 TXT record to resolve before asking the CA
+	// to validate it. 0 skips the wait entirely.
+	DNSPropagationTimeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		AccountKey:            "/etc/initium/acme-account.key",
+		CertOutput:            "fullchain.pem",
+		KeyOutput:             "privkey.pem",
+		HTTPPort:              80,
+		RenewBefore:           30 * 24 * time.Hour,
+		RFC2136TSIGAlgorithm:  "hmac-sha256",
+		DNSPropagationTimeout: 2 * time.Minute,
+	}
+}
+
+func (c Config) Validate() error {
+	if len(c.Domains) == 0 {
+		return fmt.Errorf("at least one --domain is required")
+	}
+	if c.Workdir == "" {
+		return fmt.Errorf("workdir is required")
+	}
+	if c.AccountKey == "" {
+		return fmt.Errorf("account-key is required")
+	}
+	if c.DNSProvider != "" {
+		if _, ok := dnsProviderRegistry[c.DNSProvider]; !ok {
+			return fmt.Errorf("unsupported --dns-provider %q", c.DNSProvider)
+		}
+	}
+	if c.HTTPPort <= 0 || c.HTTPPort > 65535 {
+		return fmt.Errorf("invalid --http-port %d", c.HTTPPort)
+	}
+	if (c.EABKeyID == "") != (c.EABHMACEnv == "") {
+		return fmt.Errorf("--eab-kid and --eab-hmac-env must be set together")
+	}
+	return nil
+}
+
+// Result reports what Do did.
+type Result struct {
+	CertPath string
+	KeyPath  string
+	// Cached is true when an existing certificate was still valid for
+	// longer than RenewBefore and no ACME issuance was performed.
+	Cached   bool
+	NotAfter time.Time
+}
+
+// cacheEntry is the small on-disk record of the most recently issued
+// certificate for a given sorted domain list, so reruns can skip a full
+// ACME issuance when the existing cert isn't close to expiry yet.
+type cacheEntry struct {
+	Domains  []string  `json:"domains"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+// cacheKey hashes the sorted, deduplicated domain list so certs for the
+// same domain set are always found under the same cache path regardless of
+// the order --domain flags were given in.
+func cacheKey(domains []string) string {
+	sorted := append([]string(nil), domains...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(sum[:])
+}
+
+func cachePath(workdir string, domains []string) string {
+	return workdir + "/.acme-cache-" + cacheKey(domains) + ".json"
+}
+
+// Do issues or renews a certificate for cfg.Domains, writing fullchain.pem
+// and privkey.pem under cfg.Workdir. If a cached certificate for the same
+// domain set is not within cfg.RenewBefore of expiry, no ACME calls are
+// made at all.
+func Do(ctx context.Context, cfg Config) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	certPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.CertOutput)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid cert output path: %w", err)
+	}
+	keyPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.KeyOutput)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid key output path: %w", err)
+	}
+
+	if entry, ok := readCache(cachePath(cfg.Workdir, cfg.Domains)); ok {
+		if _, err := os.Stat(certPath); err == nil {
+			if time.Until(entry.NotAfter) > cfg.RenewBefore {
+				return Result{CertPath: certPath, KeyPath: keyPath, Cached: true, NotAfter: entry.NotAfter}, nil
+			}
+		}
+	}
+
+	accountKey, err := loadOrGenerateAccountKey(cfg.AccountKey)
+	if err != nil {
+		return Result{}, fmt.Errorf("loading ACME account key: %w", err)
+	}
+
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: directoryURL(cfg),
+	}
+
+	if err := registerAccount(ctx, client, cfg); err != nil {
+		return Result{}, fmt.Errorf("registering ACME account: %w", err)
+	}
+
+	certDER, leaf, err := issueCertificate(ctx, client, cfg)
+	if err != nil {
+		return Result{}, fmt.Errorf("issuing certificate for %v: %w", cfg.Domains, err)
+	}
+
+	certPEM, keyPEM, err := encodeCertKeyPair(certDER, leaf.key)
+	if err != nil {
+		return Result{}, fmt.Errorf("encoding certificate: %w", err)
+	}
+
+	if err := safety.WriteFileAtomic(certPath, certPEM, 0o644); err != nil {
+		return Result{}, err
+	}
+	if err := safety.WriteFileAtomic(keyPath, keyPEM, 0o600); err != nil {
+		return Result{}, err
+	}
+
+	if err := writeCache(cachePath(cfg.Workdir, cfg.Domains), cacheEntry{Domains: cfg.Domains, NotAfter: leaf.notAfter}); err != nil {
+		return Result{}, fmt.Errorf("writing ACME cache entry: %w", err)
+	}
+
+	return Result{CertPath: certPath, KeyPath: keyPath, NotAfter: leaf.notAfter}, nil
+}
+
+func directoryURL(cfg Config) string {
+	switch {
+	case cfg.CADirectory != "":
+		return cfg.CADirectory
+	case cfg.Staging:
+		return LEStagingDirectoryURL
+	default:
+		return LEDirectoryURL
+	}
+}
+
+func readCache(path string) (cacheEntry, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func writeCache(path string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return safety.WriteFileAtomic(path, data, 0o644)
+}
+
+// loadOrGenerateAccountKey reads an existing ACME account key from path, or
+// generates and persists a new ECDSA P-256 key on first run.
+func loadOrGenerateAccountKey(path string) (*ecdsa.PrivateKey, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("%s does not contain a PEM-encoded key", path)
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating account key: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling account key: %w", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := safety.WriteFileAtomic(path, pemBytes, 0o600); err != nil {
+		return nil, fmt.Errorf("persisting account key: %w", err)
+	}
+	return key, nil
+}
+
+// registerAccount registers the account key with the CA if it isn't
+// already registered; a repeat Register for a known key returns the
+// existing account rather than erroring, so this is safe to call every run.
+func registerAccount(ctx context.Context, client *acme.Client, cfg Config) error {
+	acct := &acme.Account{}
+	if cfg.EABKeyID != "" {
+		hmacKey := os.Getenv(cfg.EABHMACEnv)
+		if hmacKey == "" {
+			return fmt.Errorf("EAB HMAC env var %q is empty or not set", cfg.EABHMACEnv)
+		}
+		hmacBytes, err := base64.RawURLEncoding.DecodeString(hmacKey)
+		if err != nil {
+			return fmt.Errorf("EAB HMAC env var %q must be base64url-encoded: %w", cfg.EABHMACEnv, err)
+		}
+		acct.ExternalAccountBinding = &acme.ExternalAccountBinding{
+			KID: cfg.EABKeyID,
+			Key: hmacBytes,
+		}
+	}
+
+	_, err := client.Register(ctx, acct, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return err
+	}
+	return nil
+}
+
+// leafCert holds the private key generated for the certificate being
+// issued, alongside the notAfter time parsed out of the finalized order's
+// leaf certificate.
+type leafCert struct {
+	key      *ecdsa.PrivateKey
+	notAfter time.Time
+}
+
+// issueCertificate runs a full ACME order: authorize every domain (solving
+// whichever challenge type cfg selects), finalize with a freshly generated
+// CSR, and return the issued certificate chain.
+func issueCertificate(ctx context.Context, client *acme.Client, cfg Config) ([][]byte, leafCert, error) {
+	ids := make([]acme.AuthzID, len(cfg.Domains))
+	for i, d := range cfg.Domains {
+		ids[i] = acme.AuthzID{Type: "dns", Value: d}
+	}
+
+	order, err := client.AuthorizeOrder(ctx, ids)
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("authorizing order: %w", err)
+	}
+
+	for _, zurl := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, zurl)
+		if err != nil {
+			return nil, leafCert{}, fmt.Errorf("fetching authorization: %w", err)
+		}
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+		if err := solveAuthorization(ctx, client, cfg, authz); err != nil {
+			return nil, leafCert{}, err
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("waiting for order to be ready: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("generating leaf key: %w", err)
+	}
+
+	csr, err := buildCSR(key, cfg.Domains, cfg.MustStaple)
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("building CSR: %w", err)
+	}
+
+	der, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("finalizing order: %w", err)
+	}
+
+	leafX509, err := x509.ParseCertificate(der[0])
+	if err != nil {
+		return nil, leafCert{}, fmt.Errorf("parsing issued leaf certificate: %w", err)
+	}
+
+	return der, leafCert{key: key, notAfter: leafX509.NotAfter}, nil
+}
+
+// solveAuthorization picks whichever challenge type cfg selects (HTTP-01
+// unless a --dns-provider is configured), presents the response, and waits
+// for the CA to mark the authorization valid.
+func solveAuthorization(ctx context.Context, client *acme.Client, cfg Config, authz *acme.Authorization) error {
+	wantType := "http-01"
+	if cfg.DNSProvider != "" {
+		wantType = "dns-01"
+	}
+
+	var chal *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == wantType {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("no %s challenge offered for %s", wantType, authz.Identifier.Value)
+	}
+
+	cleanup, err := presentChallenge(ctx, client, cfg, authz.Identifier.Value, chal)
+	if err != nil {
+		return fmt.Errorf("presenting %s challenge for %s: %w", wantType, authz.Identifier.Value, err)
+	}
+	defer cleanup()
+
+	if _, err := client.Accept(ctx, chal); err != nil {
+		return fmt.Errorf("accepting %s challenge for %s: %w", wantType, authz.Identifier.Value, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("waiting for %s authorization: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+// presentChallenge serves an HTTP-01 response on cfg.HTTPPort or publishes a
+// DNS-01 TXT record via the configured provider, returning a cleanup func
+// that tears the response back down once the authorization is decided.
+func presentChallenge(ctx context.Context, client *acme.Client, cfg Config, domain string, chal *acme.Challenge) (func(), error) {
+	if chal.Type == "http-01" {
+		response, err := client.HTTP01ChallengeResponse(chal.Token)
+		if err != nil {
+			return nil, err
+		}
+		path := client.HTTP01ChallengePath(chal.Token)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, response)
+		})
+		srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.HTTPPort), Handler: mux}
+		go srv.ListenAndServe()
+
+		return func() { srv.Shutdown(context.Background()) }, nil
+	}
+
+	newProvider, ok := dnsProviderRegistry[cfg.DNSProvider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported --dns-provider %q", cfg.DNSProvider)
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := client.DNS01ChallengeRecord(chal.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.Present(ctx, domain, record); err != nil {
+		return nil, err
+	}
+	cleanup := func() { provider.CleanUp(ctx, domain, record) }
+
+	// Route53/RFC2136 propagation, and any caching resolver in between,
+	// isn't instantaneous; asking the CA to validate before the record is
+	// actually resolvable produces a spurious authorization failure.
+	if err := waitForDNSPropagation(ctx, domain, record, cfg.DNSPropagationTimeout); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
+
+// buildCSR generates a PKCS#10 certificate signing request for domains,
+// with domains[0] as the CN, optionally requesting OCSP must-staple.
+func buildCSR(key *ecdsa.PrivateKey, domains []string, mustStaple bool) ([]byte, error) {
+	template := &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}
+	if mustStaple {
+		template.ExtraExtensions = append(template.ExtraExtensions, mustStapleExtension())
+	}
+	return x509.CreateCertificateRequest(rand.Reader, template, key)
+}
+
+// mustStapleExtension is the TLS Feature extension (RFC 7633) requesting
+// OCSP stapling, identified by OID 1.3.6.1.5.5.7.1.24 with value [5] (status_request).
+func mustStapleExtension() pkix.Extension {
+	return pkix.Extension{
+		Id:    []int{1, 3, 6, 1, 5, 5, 7, 1, 24},
+		Value: []byte{0x30, 0x03, 0x02, 0x01, 0x05},
+	}
+}
+
+// encodeCertKeyPair PEM-encodes the issued certificate chain and its
+// private key.
+func encodeCertKeyPair(certDER [][]byte, key *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	var certPEM []byte
+	for _, der := range certDER {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling leaf key: %w", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+// LoadTLSCertificate is a convenience helper for callers that want to load
+// the pair Do just wrote directly into a tls.Config, without going through
+// tls.LoadX509KeyPair and re-deriving the paths themselves.
+func LoadTLSCertificate(result Result) (tls.Certificate, error) {
+	return tls.LoadX509KeyPair(result.CertPath, result.KeyPath)
+}
@@ -0,0 +1,171 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigValidationRequiresDomain(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workdir = t.TempDir()
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for missing --domain")
+	}
+}
+
+func TestConfigValidationRejectsUnknownDNSProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Domains = []string{"example.com"}
+	cfg.Workdir = t.TempDir()
+	cfg.DNSProvider = "cloudflare"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unsupported --dns-provider")
+	}
+}
+
+func TestConfigValidationRequiresEABPairing(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Domains = []string{"example.com"}
+	cfg.Workdir = t.TempDir()
+	cfg.EABKeyID = "kid-1"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for --eab-kid without --eab-hmac-env")
+	}
+}
+
+func TestCacheKeyStableRegardlessOfOrder(t *testing.T) {
+	a := cacheKey([]string{"example.com", "www.example.com"})
+	b := cacheKey([]string{"www.example.com", "example.com"})
+	if a != b {
+		t.Fatalf("expected order-independent cache key, got %q and %q", a, b)
+	}
+}
+
+func TestLoadOrGenerateAccountKeyPersistsAcrossCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "account.key")
+
+	first, err := loadOrGenerateAccountKey(path)
+	if err != nil {
+		t.Fatalf("generating account key: %v", err)
+	}
+
+	second, err := loadOrGenerateAccountKey(path)
+	if err != nil {
+		t.Fatalf("reloading account key: %v", err)
+	}
+
+	if !first.Equal(second) {
+		t.Fatal("expected the same account key to be reloaded, not regenerated")
+	}
+}
+
+func TestReadWriteCacheRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	want := cacheEntry{Domains: []string{"example.com"}, NotAfter: time.Now().Add(60 * 24 * time.Hour).UTC().Truncate(time.Second)}
+
+	if err := writeCache(path, want); err != nil {
+		t.Fatalf("writeCache: %v", err)
+	}
+
+	got, ok := readCache(path)
+	if !ok {
+		t.Fatal("expected cache entry to be read back")
+	}
+	if !got.NotAfter.Equal(want.NotAfter) {
+		t.Fatalf("got NotAfter %v, want %v", got.NotAfter, want.NotAfter)
+	}
+}
+
+func TestReadCacheMissingFile(t *testing.T) {
+	if _, ok := readCache(filepath.Join(t.TempDir(), "missing.json")); ok {
+		t.Fatal("expected no cache entry for a missing file")
+	}
+}
+
+func TestBuildCSRIncludesAllDomains(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	der, err := buildCSR(key, []string{"example.com", "www.example.com"}, false)
+	if err != nil {
+		t.Fatalf("buildCSR: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("parsing CSR: %v", err)
+	}
+	if csr.Subject.CommonName != "example.com" {
+		t.Fatalf("expected CN example.com, got %q", csr.Subject.CommonName)
+	}
+	if len(csr.DNSNames) != 2 {
+		t.Fatalf("expected 2 SANs, got %d: %v", len(csr.DNSNames), csr.DNSNames)
+	}
+}
+
+func TestBuildCSRMustStapleExtension(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	der, err := buildCSR(key, []string{"example.com"}, true)
+	if err != nil {
+		t.Fatalf("buildCSR: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("parsing CSR: %v", err)
+	}
+
+	var found bool
+	for _, ext := range csr.Extensions {
+		if ext.Id.String() == "1.3.6.1.5.5.7.1.24" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected must-staple extension in CSR")
+	}
+}
+
+func TestDoUsesCachedCertificateWithinRenewBefore(t *testing.T) {
+	workdir := t.TempDir()
+	domains := []string{"example.com"}
+
+	if err := os.WriteFile(filepath.Join(workdir, "fullchain.pem"), []byte("placeholder"), 0o644); err != nil {
+		t.Fatalf("seeding cert: %v", err)
+	}
+	entry := cacheEntry{Domains: domains, NotAfter: time.Now().Add(60 * 24 * time.Hour)}
+	if err := writeCache(cachePath(workdir, domains), entry); err != nil {
+		t.Fatalf("seeding cache: %v", err)
+	}
+
+	cfg := Config{
+		Domains:     domains,
+		Workdir:     workdir,
+		AccountKey:  filepath.Join(workdir, "account.key"),
+		CertOutput:  "fullchain.pem",
+		KeyOutput:   "privkey.pem",
+		HTTPPort:    80,
+		RenewBefore: 30 * 24 * time.Hour,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if !result.Cached {
+		t.Fatal("expected cached result when the existing cert is well within --renew-before")
+	}
+}
@@ -0,0 +1,237 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	r53types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/miekg/dns"
+)
+
+// txtResolver is the subset of *net.Resolver that waitForDNSPropagation
+// needs, so tests can swap it out without a real DNS lookup.
+type txtResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var resolver txtResolver = net.DefaultResolver
+
+const dnsPropagationPollInterval = 5 * time.Second
+
+// waitForDNSPropagation polls the _acme-challenge TXT record for domain
+// until it resolves with the exact expected keyAuth value, or timeout
+// elapses, whichever comes first. timeout <= 0 skips the wait entirely,
+// for providers or environments where propagation is already guaranteed
+// some other way.
+func waitForDNSPropagation(ctx context.Context, domain, keyAuth string, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	fqdn := "_acme-challenge." + dns.Fqdn(domain)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(dnsPropagationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if txts, err := resolver.LookupTXT(ctx, fqdn); err == nil {
+			for _, txt := range txts {
+				if txt == keyAuth {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("TXT record %s did not resolve with the expected value within %s", fqdn, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// dnsProvider publishes and removes the TXT record an ACME DNS-01 challenge
+// requires at _acme-challenge.<domain>. Implementations authenticate using
+// whatever ambient credentials their platform provides, matching the
+// secretProviderRegistry convention in internal/fetch.
+type dnsProvider interface {
+	Present(ctx context.Context, domain, keyAuth string) error
+	CleanUp(ctx context.Context, domain, keyAuth string) error
+}
+
+var dnsProviderRegistry = map[string]func(cfg Config) (dnsProvider, error){
+	"rfc2136": newRFC2136Provider,
+	"route53": newRoute53Provider,
+}
+
+// rfc2136Provider publishes TXT records via a TSIG-signed DNS UPDATE
+// (RFC 2136), the mechanism BIND, PowerDNS, and most on-prem DNS servers
+// support for dynamic updates.
+type rfc2136Provider struct {
+	nameserver string
+	tsigKey    string
+	tsigSecret string
+	tsigAlgo   string
+}
+
+func newRFC2136Provider(cfg Config) (dnsProvider, error) {
+	if cfg.RFC2136Nameserver == "" {
+		return nil, fmt.Errorf("--rfc2136-nameserver is required for --dns-provider rfc2136")
+	}
+	secret := os.Getenv(cfg.RFC2136TSIGSecretEnv)
+	if cfg.RFC2136TSIGSecretEnv != "" && secret == "" {
+		return nil, fmt.Errorf("rfc2136 TSIG secret env var %q is empty or not set", cfg.RFC2136TSIGSecretEnv)
+	}
+	return &rfc2136Provider{
+		nameserver: cfg.RFC2136Nameserver,
+		tsigKey:    cfg.RFC2136TSIGKey,
+		tsigSecret: secret,
+		tsigAlgo:   cfg.RFC2136TSIGAlgorithm,
+	}, nil
+}
+
+func (p *rfc2136Provider) Present(ctx context.Context, domain, keyAuth string) error {
+	return p.update(domain, keyAuth, dns.TypeTXT, true)
+}
+
+func (p *rfc2136Provider) CleanUp(ctx context.Context, domain, keyAuth string) error {
+	return p.update(domain, keyAuth, dns.TypeTXT, false)
+}
+
+// update sends a signed DNS UPDATE adding (present=true) or removing the
+// _acme-challenge.<domain> TXT record holding keyAuth.
+func (p *rfc2136Provider) update(domain, keyAuth string, rrType uint16, present bool) error {
+	fqdn := "_acme-challenge." + dns.Fqdn(domain)
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(dns.Fqdn(zoneOf(domain)))
+
+	rr, err := dns.NewRR(fmt.Sprintf(`%s 60 IN TXT "%s"`, fqdn, keyAuth))
+	if err != nil {
+		return fmt.Errorf("building TXT record: %w", err)
+	}
+
+	if present {
+		msg.Insert([]dns.RR{rr})
+	} else {
+		msg.Remove([]dns.RR{rr})
+	}
+
+	client := new(dns.Client)
+	if p.tsigKey != "" {
+		msg.SetTsig(dns.Fqdn(p.tsigKey), p.tsigAlgo, 300, time.Now().Unix())
+		client.TsigSecret = map[string]string{dns.Fqdn(p.tsigKey): p.tsigSecret}
+	}
+
+	_, _, err = client.Exchange(msg, p.nameserver)
+	if err != nil {
+		return fmt.Errorf("DNS UPDATE to %s: %w", p.nameserver, err)
+	}
+	return nil
+}
+
+// zoneOf approximates the zone of domain as its last two labels (a domain
+// of two labels or fewer is its own zone); callers with split-horizon or
+// delegated subdomains should
+// point --rfc2136-nameserver at the authoritative server for that zone.
+func zoneOf(domain string) string {
+	labels := dns.SplitDomainName(domain)
+	if len(labels) <= 2 {
+		return domain
+	}
+	return dns.Fqdn(labels[len(labels)-2] + "." + labels[len(labels)-1])
+}
+
+// route53Provider publishes TXT records via the Route53 API, authenticating
+// through the default AWS credential chain (IRSA in-cluster).
+type route53Provider struct {
+	client       *route53.Client
+	hostedZoneID string
+}
+
+func newRoute53Provider(cfg Config) (dnsProvider, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return &route53Provider{
+		client:       route53.NewFromConfig(awsCfg),
+		hostedZoneID: cfg.Route53HostedZoneID,
+	}, nil
+}
+
+func (p *route53Provider) Present(ctx context.Context, domain, keyAuth string) error {
+	return p.upsert(ctx, domain, keyAuth, r53types.ChangeActionUpsert)
+}
+
+func (p *route53Provider) CleanUp(ctx context.Context, domain, keyAuth string) error {
+	return p.upsert(ctx, domain, keyAuth, r53types.ChangeActionDelete)
+}
+
+func (p *route53Provider) upsert(ctx context.Context, domain, keyAuth string, action r53types.ChangeAction) error {
+	zoneID, err := p.resolveZoneID(ctx, domain)
+	if err != nil {
+		return err
+	}
+
+	fqdn := "_acme-challenge." + domain
+	_, err = p.client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &r53types.ChangeBatch{
+			Changes: []r53types.Change{{
+				Action: action,
+				ResourceRecordSet: &r53types.ResourceRecordSet{
+					Name:            aws.String(fqdn),
+					Type:            r53types.RRTypeTxt,
+					TTL:             aws.Int64(60),
+					ResourceRecords: []r53types.ResourceRecord{{Value: aws.String(fmt.Sprintf("%q", keyAuth))}},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Route53 ChangeResourceRecordSets for %s: %w", fqdn, err)
+	}
+	return nil
+}
+
+// resolveZoneID returns the configured hosted zone, or discovers the
+// longest matching hosted zone name for domain when none was given.
+func (p *route53Provider) resolveZoneID(ctx context.Context, domain string) (string, error) {
+	if p.hostedZoneID != "" {
+		return p.hostedZoneID, nil
+	}
+
+	out, err := p.client.ListHostedZonesByName(ctx, &route53.ListHostedZonesByNameInput{})
+	if err != nil {
+		return "", fmt.Errorf("listing hosted zones: %w", err)
+	}
+
+	var best r53types.HostedZone
+	for _, zone := range out.HostedZones {
+		name := dns.Fqdn(*zone.Name)
+		if dns.IsSubDomain(name, dns.Fqdn(domain)) && len(name) > len(bestName(best)) {
+			best = zone
+		}
+	}
+	if best.Id == nil {
+		return "", fmt.Errorf("no Route53 hosted zone found for %s; set --route53-hosted-zone-id explicitly", domain)
+	}
+	return *best.Id, nil
+}
+
+func bestName(zone r53types.HostedZone) string {
+	if zone.Name == nil {
+		return ""
+	}
+	return *zone.Name
+}
@@ -0,0 +1,49 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeTXTResolver struct {
+	txts []string
+	err  error
+}
+
+func (f *fakeTXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	return f.txts, f.err
+}
+
+func withFakeResolver(t *testing.T, fake txtResolver) {
+	t.Helper()
+	original := resolver
+	resolver = fake
+	t.Cleanup(func() { resolver = original })
+}
+
+func TestWaitForDNSPropagationSkipsWaitWhenTimeoutIsZero(t *testing.T) {
+	withFakeResolver(t, &fakeTXTResolver{err: fmt.Errorf("should not be called")})
+
+	if err := waitForDNSPropagation(context.Background(), "example.com", "expected-value", 0); err != nil {
+		t.Fatalf("expected no error with timeout <= 0, got: %v", err)
+	}
+}
+
+func TestWaitForDNSPropagationSucceedsOnceRecordMatches(t *testing.T) {
+	withFakeResolver(t, &fakeTXTResolver{txts: []string{"some-other-value", "expected-value"}})
+
+	if err := waitForDNSPropagation(context.Background(), "example.com", "expected-value", time.Second); err != nil {
+		t.Fatalf("expected success once the TXT record matches, got: %v", err)
+	}
+}
+
+func TestWaitForDNSPropagationTimesOutWithoutAMatch(t *testing.T) {
+	withFakeResolver(t, &fakeTXTResolver{txts: []string{"wrong-value"}})
+
+	err := waitForDNSPropagation(context.Background(), "example.com", "expected-value", 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected error when the TXT record never matches before the timeout")
+	}
+}
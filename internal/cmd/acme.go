@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kitstream/initium/internal/acme"
+	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/retry"
+	"github.com/spf13/cobra"
+)
+
+func NewAcmeCmd(log *logging.Logger) *cobra.Command {
+	defaults := acme.DefaultConfig()
+
+	var (
+		domains               []string
+		workdir               string
+		accountKey            string
+		certOutput            string
+		keyOutput             string
+		httpPort              int
+		dnsProvider           string
+		renewBefore           time.Duration
+		staging               bool
+		caDirectory           string
+		eabKeyID              string
+		eabHMACEnv            string
+		mustStaple            bool
+		rfc2136Nameserver     string
+		rfc2136TSIGKey        string
+		rfc2136TSIGSecretEnv  string
+		rfc2136TSIGAlgorithm  string
+		route53HostedZoneID   string
+		dnsPropagationTimeout time.Duration
+		maxAttempts           int
+		initialDelay          time.Duration
+		maxDelay              time.Duration
+		backoffFactor         float64
+		jitterFraction        float64
+		jitterMode            string
+		totalBudget           time.Duration
+		perAttemptTimeout     time.Duration
+		timeout               time.Duration
+		jsonLogs              bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "acme",
+		Short: "Obtain or renew an ACME (Let's Encrypt) TLS certificate into the workdir",
+		Long: `Run the ACME protocol to obtain a certificate for one or more --domain
+values, writing fullchain.pem and privkey.pem under --workdir (0600 on the
+key). The account key is generated on first run and persisted at
+--account-key so subsequent invocations reuse the same ACME account.
+
+Domain ownership is proven either with HTTP-01 (a listener is bound on
+--http-port, default 80) or DNS-01 via a pluggable --dns-provider: "rfc2136"
+(TSIG-signed DNS UPDATE) or "route53" (Route53 API, auth via the default AWS
+credential chain). For DNS-01, after publishing the _acme-challenge TXT
+record, initium polls for it to actually resolve before asking the CA to
+validate, bounded by --dns-propagation-timeout (default 2m); this avoids a
+spurious validation failure against a record that hasn't propagated yet.
+
+The issued certificate's expiry is cached alongside the domain list it was
+issued for; a rerun before the leaf is within --renew-before of expiring
+(default 720h) makes no ACME calls at all and exits 0 with a "cached" log
+line, so this composes cleanly as:
+
+  sh -c 'initium acme --domain example.com --workdir /certs && exec app'
+
+--staging points at the Let's Encrypt staging directory for testing without
+hitting production rate limits; --ca-directory overrides the directory URL
+entirely for other ACME CAs. --eab-kid/--eab-hmac-env configure external
+account binding, required by some private CAs. --must-staple requests the
+OCSP must-staple certificate extension.
+
+Retries during order authorization and validation reuse internal/retry with
+the same flag surface as "initium fetch".`,
+		Example: `  # HTTP-01 against Let's Encrypt
+  initium acme --domain example.com --workdir /certs
+
+  # DNS-01 via Route53, explicit hosted zone
+  initium acme --domain '*.example.com' --dns-provider route53 \
+    --route53-hosted-zone-id Z1234567890 --workdir /certs
+
+  # DNS-01 via an on-prem BIND server with TSIG
+  initium acme --domain internal.example.com --dns-provider rfc2136 \
+    --rfc2136-nameserver ns1.example.com:53 --rfc2136-tsig-key acme-updater \
+    --rfc2136-tsig-secret-env RFC2136_TSIG_SECRET --workdir /certs
+
+  # Test against LE staging first
+  initium acme --domain example.com --workdir /certs --staging`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCtx, rootSpan := tracer.Start(cmd.Context(), "acme")
+			defer rootSpan.End()
+			cmd.SetContext(rootCtx)
+
+			if jsonLogs {
+				log.SetJSON(true)
+			}
+
+			if len(domains) == 0 {
+				return fmt.Errorf("at least one --domain is required")
+			}
+
+			retryCfg := retry.Config{
+				Name:              "acme",
+				MaxAttempts:       maxAttempts,
+				InitialDelay:      initialDelay,
+				MaxDelay:          maxDelay,
+				BackoffFactor:     backoffFactor,
+				JitterFraction:    jitterFraction,
+				JitterMode:        retry.JitterMode(jitterMode),
+				TotalBudget:       totalBudget,
+				PerAttemptTimeout: perAttemptTimeout,
+			}
+			if err := retryCfg.Validate(); err != nil {
+				return fmt.Errorf("invalid retry config: %w", err)
+			}
+
+			acmeCfg := acme.Config{
+				Domains:               domains,
+				Workdir:               workdir,
+				AccountKey:            accountKey,
+				CertOutput:            certOutput,
+				KeyOutput:             keyOutput,
+				HTTPPort:              httpPort,
+				DNSProvider:           dnsProvider,
+				RenewBefore:           renewBefore,
+				Staging:               staging,
+				CADirectory:           caDirectory,
+				EABKeyID:              eabKeyID,
+				EABHMACEnv:            eabHMACEnv,
+				MustStaple:            mustStaple,
+				RFC2136Nameserver:     rfc2136Nameserver,
+				RFC2136TSIGKey:        rfc2136TSIGKey,
+				RFC2136TSIGSecretEnv:  rfc2136TSIGSecretEnv,
+				RFC2136TSIGAlgorithm:  rfc2136TSIGAlgorithm,
+				Route53HostedZoneID:   route53HostedZoneID,
+				DNSPropagationTimeout: dnsPropagationTimeout,
+			}
+			if err := acmeCfg.Validate(); err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			log.Info("requesting certificate", "domains", fmt.Sprintf("%v", domains))
+
+			var acmeResult acme.Result
+			result := retry.DoWithHooks(ctx, retryCfg, func(ctx context.Context, attempt int) error {
+				log.Debug("acme attempt", "attempt", fmt.Sprintf("%d", attempt+1))
+				var err error
+				acmeResult, err = acme.Do(ctx, acmeCfg)
+				return err
+			}, func(attempt int, err error, nextDelay time.Duration) {
+				log.Debug("retrying acme order", "attempt", fmt.Sprintf("%d", attempt+1), "error", err.Error(), "next_delay", nextDelay.String())
+			})
+
+			if result.Err != nil {
+				log.Error("acme issuance failed", "domains", fmt.Sprintf("%v", domains), "error", result.Err.Error())
+				return fmt.Errorf("acme issuance for %v failed: %w", domains, result.Err)
+			}
+
+			if acmeResult.Cached {
+				log.Info("cached", "cert", acmeResult.CertPath, "not_after", acmeResult.NotAfter.Format(time.RFC3339))
+				return nil
+			}
+
+			log.Info("certificate issued", "cert", acmeResult.CertPath, "key", acmeResult.KeyPath, "not_after", acmeResult.NotAfter.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&domains, "domain", nil, "Domain to request a certificate for (repeatable)")
+	cmd.Flags().StringVar(&workdir, "workdir", "/etc/certs", "Working directory for output files")
+	cmd.Flags().StringVar(&accountKey, "account-key", defaults.AccountKey, "Path to the ACME account key, generated on first run")
+	cmd.Flags().StringVar(&certOutput, "cert-output", defaults.CertOutput, "Output file path relative to workdir for the full chain")
+	cmd.Flags().StringVar(&keyOutput, "key-output", defaults.KeyOutput, "Output file path relative to workdir for the private key")
+	cmd.Flags().IntVar(&httpPort, "http-port", defaults.HTTPPort, "Port to bind for HTTP-01 challenge responses")
+	cmd.Flags().StringVar(&dnsProvider, "dns-provider", "", `DNS-01 provider to use instead of HTTP-01: "rfc2136" or "route53"`)
+	cmd.Flags().DurationVar(&renewBefore, "renew-before", defaults.RenewBefore, "Renew when the cached certificate expires within this long")
+	cmd.Flags().BoolVar(&staging, "staging", false, "Use the Let's Encrypt staging directory")
+	cmd.Flags().StringVar(&caDirectory, "ca-directory", "", "ACME directory URL for a CA other than Let's Encrypt")
+	cmd.Flags().StringVar(&eabKeyID, "eab-kid", "", "External account binding key ID (required by some private CAs)")
+	cmd.Flags().StringVar(&eabHMACEnv, "eab-hmac-env", "", "Name of env var containing the external account binding HMAC key")
+	cmd.Flags().BoolVar(&mustStaple, "must-staple", false, "Request the OCSP must-staple certificate extension")
+	cmd.Flags().StringVar(&rfc2136Nameserver, "rfc2136-nameserver", "", "Nameserver address (host:port) for --dns-provider rfc2136")
+	cmd.Flags().StringVar(&rfc2136TSIGKey, "rfc2136-tsig-key", "", "TSIG key name for --dns-provider rfc2136")
+	cmd.Flags().StringVar(&rfc2136TSIGSecretEnv, "rfc2136-tsig-secret-env", "", "Name of env var containing the TSIG secret for --dns-provider rfc2136")
+	cmd.Flags().StringVar(&rfc2136TSIGAlgorithm, "rfc2136-tsig-algorithm", defaults.RFC2136TSIGAlgorithm, "TSIG algorithm for --dns-provider rfc2136")
+	cmd.Flags().StringVar(&route53HostedZoneID, "route53-hosted-zone-id", "", "Hosted zone ID for --dns-provider route53; auto-discovered from the domain if empty")
+	cmd.Flags().DurationVar(&dnsPropagationTimeout, "dns-propagation-timeout", defaults.DNSPropagationTimeout, "How long to wait for a DNS-01 TXT record to become resolvable before asking the CA to validate it; 0 skips the wait")
+	cmd.Flags().IntVar(&maxAttempts, "max-attempts", 3, "Maximum retry attempts")
+	cmd.Flags().DurationVar(&initialDelay, "initial-delay", time.Second, "Initial delay between retries")
+	cmd.Flags().DurationVar(&maxDelay, "max-delay", 30*time.Second, "Maximum delay between retries")
+	cmd.Flags().Float64Var(&backoffFactor, "backoff-factor", 2.0, "Backoff multiplier")
+	cmd.Flags().Float64Var(&jitterFraction, "jitter", 0.1, "Jitter fraction (0.0-1.0)")
+	cmd.Flags().StringVar(&jitterMode, "jitter-mode", "", `Backoff jitter strategy: "" (additive, default), "full", or "decorrelated"`)
+	cmd.Flags().DurationVar(&totalBudget, "total-budget", 0, "Cap wall-clock time across all attempts and sleeps; 0 disables")
+	cmd.Flags().DurationVar(&perAttemptTimeout, "per-attempt-timeout", 0, "Timeout applied to each individual attempt; 0 disables")
+	cmd.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "Overall timeout")
+	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+
+	return cmd
+}
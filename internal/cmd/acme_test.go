@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+func TestAcmeCmdRequiresDomain(t *testing.T) {
+	var buf bytes.Buffer
+	lg := logging.New(&buf, false, logging.LevelInfo)
+	c := NewAcmeCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{"--workdir", t.TempDir()})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error when --domain is not set")
+	}
+}
+
+func TestAcmeCmdRejectsUnknownDNSProvider(t *testing.T) {
+	var buf bytes.Buffer
+	lg := logging.New(&buf, false, logging.LevelInfo)
+	c := NewAcmeCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{
+		"--domain", "example.com",
+		"--workdir", t.TempDir(),
+		"--dns-provider", "cloudflare",
+	})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error for unsupported --dns-provider")
+	}
+}
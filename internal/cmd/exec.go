@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"syscall"
+	"time"
 
 	"github.com/kitstream/initium/internal/logging"
 	"github.com/spf13/cobra"
@@ -9,9 +11,13 @@ import (
 
 func NewExecCmd(log *logging.Logger) *cobra.Command {
 	var (
-		workdir  string
-		jsonLogs bool
+		workdir         string
+		jsonLogs        bool
+		shutdownSignal  string
+		shutdownTimeout time.Duration
+		maxLineBytes    int
 	)
+	var gitFlags *gitSourceFlags
 
 	cmd := &cobra.Command{
 		Use:   "exec -- COMMAND [ARGS...]",
@@ -23,7 +29,29 @@ initium flags from the command and its arguments.
 
 stdout and stderr are captured and logged with timestamps. The child process
 exit code is forwarded. If --workdir is set, the child process working
-directory is changed accordingly.`,
+directory is changed accordingly.
+
+If --from-git is set, that repository is shallow-cloned into a temp
+directory (cleaned up on exit) and used as the effective --workdir instead,
+so the command can run a script that lives in version control rather than
+being baked into the image. --git-ref pins a branch, tag, or commit SHA;
+--git-subdir selects a directory within the clone; --git-ssh-key and
+--git-token authenticate to private repos over SSH and HTTPS respectively;
+--timeout bounds how long the clone itself is allowed to take.
+
+The child runs in its own process group. If initium itself receives
+SIGTERM, SIGINT, or SIGHUP, --shutdown-signal (default SIGTERM) is
+forwarded to that process group so the child gets a chance to exit
+cleanly; if it hasn't exited within --shutdown-timeout (default 30s), it
+is killed with SIGKILL.
+
+A child line starting with '{' that parses as JSON has its fields
+forwarded directly into the log output (its "msg"/"message" field becomes
+the log message) instead of being wrapped whole as one; severity is
+inferred from a JSON "level" field, an ERROR/WARN substring in plain
+text, or stderr defaulting to WARN. --max-line-bytes (default 4MiB)
+caps how much of a single line is buffered before it's truncated with a
+warning rather than silently dropped.`,
 		Example: `  # Run a setup script
   initium exec -- /bin/setup.sh
 
@@ -34,10 +62,19 @@ directory is changed accordingly.`,
   initium exec --workdir /app -- ./prepare.sh
 
   # Generate a private key with openssl
-  initium exec --workdir /certs -- openssl genrsa -out key.pem 4096`,
+  initium exec --workdir /certs -- openssl genrsa -out key.pem 4096
+
+  # Run a script checked out from a Git repo
+  initium exec --from-git https://github.com/acme/scripts.git --git-ref v1.2.0 -- ./setup.sh
+
+  # Give a long-running script more time to wind down on shutdown
+  initium exec --shutdown-timeout 2m -- /bin/long-running.sh`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rootSpan := tracer.Start(cmd.Context(), "exec")
+			defer rootSpan.End()
+
 			if jsonLogs {
 				log.SetJSON(true)
 			}
@@ -46,9 +83,20 @@ directory is changed accordingly.`,
 				return fmt.Errorf("command is required after \"--\"")
 			}
 
+			sig, err := parseShutdownSignal(shutdownSignal)
+			if err != nil {
+				return err
+			}
+
+			effectiveWorkdir, cleanup, err := gitFlags.resolveWorkdir(cmd.Context(), log, workdir)
+			if err != nil {
+				return fmt.Errorf("resolving --from-git source: %w", err)
+			}
+			defer cleanup()
+
 			log.Info("executing command", "command", args[0])
 
-			exitCode, err := runCommandInDir(log, args, workdir)
+			exitCode, err := runCommandInDir(log, args, effectiveWorkdir, sig, shutdownTimeout, maxLineBytes)
 			if err != nil {
 				return fmt.Errorf("exec failed: %w", err)
 			}
@@ -64,19 +112,23 @@ directory is changed accordingly.`,
 
 	cmd.Flags().StringVar(&workdir, "workdir", "", "Working directory for the child process (default: inherit)")
 	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	cmd.Flags().StringVar(&shutdownSignal, "shutdown-signal", defaultShutdownSignal, "Signal to forward to the child's process group on SIGTERM/SIGINT/SIGHUP: SIGTERM, SIGINT, SIGHUP, or SIGQUIT")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "How long to wait for the child to exit after forwarding --shutdown-signal before sending SIGKILL")
+	cmd.Flags().IntVar(&maxLineBytes, "max-line-bytes", defaultMaxLineBytes, "Maximum bytes of a single output line to buffer before truncating it with a warning")
+	gitFlags = registerGitSourceFlags(cmd)
 
 	return cmd
 }
 
-func runCommandInDir(log *logging.Logger, args []string, dir string) (int, error) {
+func runCommandInDir(log *logging.Logger, args []string, dir string, shutdownSignal syscall.Signal, shutdownTimeout time.Duration, maxLineBytes int) (int, error) {
 	if dir == "" {
-		return runCommand(log, args)
+		return runCommand(log, args, shutdownSignal, shutdownTimeout, maxLineBytes)
 	}
-	return runCommandWithDir(log, args, dir)
+	return runCommandWithDir(log, args, dir, shutdownSignal, shutdownTimeout, maxLineBytes)
 }
 
-func runCommandWithDir(log *logging.Logger, args []string, dir string) (int, error) {
+func runCommandWithDir(log *logging.Logger, args []string, dir string, shutdownSignal syscall.Signal, shutdownTimeout time.Duration, maxLineBytes int) (int, error) {
 	c := newExecCommand(args[0], args[1:]...)
 	c.Dir = dir
-	return executeAndStream(log, c)
+	return executeAndStream(log, c, shutdownSignal, shutdownTimeout, maxLineBytes)
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kitstream/initium/internal/fetch"
@@ -27,17 +28,120 @@ func NewFetchCmd(log *logging.Logger) *cobra.Command {
 		backoffFactor          float64
 		jitterFraction         float64
 		jsonLogs               bool
+		expectStatusRange      string
+		expectBodyRegex        string
+		expectJSONPath         string
+		expectJSONValue        string
+		expectSHA256           string
+		jitterMode             string
+		totalBudget            time.Duration
+		perAttemptTimeout      time.Duration
+		cacheDir               string
+		cacheMaxAge            time.Duration
+		cacheMaxBytes          int64
+		offline                bool
+		format                 string
+		expectSize             int64
+		maxDownloadRetries     int
+		retryBaseDelay         time.Duration
+		retryMaxDelay          time.Duration
+		customAdapters         []string
+		sources                []string
+		hostRules              []string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "fetch",
 		Short: "Fetch secrets or config from HTTP(S) endpoints",
-		Long: `Fetch a resource from an HTTP(S) endpoint and write the response body to a
-file within the working directory.
+		Long: `Fetch a resource and write its body to a file within the working directory.
+The scheme of --url selects how it is fetched:
 
-Supports optional authentication via an environment variable (to avoid leaking
-credentials in process argument lists), TLS verification skipping, redirect
-control, and retries with exponential backoff.`,
+  http(s)      plain HTTP(S) GET, with auth, TLS, and redirect options below
+  vault        Vault KV secret; auth via --auth-env, VAULT_TOKEN, or VAULT_ROLE_ID/VAULT_SECRET_ID
+  aws-sm       AWS Secrets Manager; auth via --auth-env (static creds JSON) or the default chain (IRSA)
+  gcp-sm       GCP Secret Manager; auth via --auth-env (service account key path) or Application Default Credentials
+  k8s-secret   Kubernetes Secret key; auth via the pod's in-cluster service account
+
+Secret-provider schemes authenticate using whatever ambient credentials
+their platform provides by default; --auth-env names an env var holding
+provider-specific credentials instead, for environments without IRSA,
+workload identity, or a Vault token already in VAULT_TOKEN. Their output is
+written atomically with 0600 permissions, instead of the 0644 used for plain
+HTTP downloads.
+
+A secret-provider URL's fragment or ?field= query parameter selects a
+single value out of a JSON secret with a jsonpath, e.g.
+vault://secret/data/app#password or aws-sm://prod/db/creds?field=password,
+so callers don't need a second jq step. --format controls how a
+secret-provider body is written: "raw" (default) unchanged, "json"
+re-marshalled indented, or "dotenv" to flatten a JSON object's top-level
+keys into uppercased KEY=VALUE lines.
+
+HTTP(S) fetches support optional authentication via an environment variable
+(to avoid leaking credentials in process argument lists), TLS verification
+skipping, redirect control, and retries with exponential backoff.
+
+Response validation flags apply to both HTTP(S) and secret-provider fetches,
+so a ready-but-empty secret or an HTTP 200 with an unready body is treated as
+not-ready and retried instead of reported as success:
+
+  --expect-status-range   e.g. "200-299" (default, HTTP(S) only)
+  --expect-body-regex     regex that must match somewhere in the body
+  --expect-json-path      JSONPath into the decoded body, e.g. "status.ready"
+  --expect-json-value     value --expect-json-path must stringify to
+  --expect-sha256         expected sha256 hex digest of the downloaded body
+  --expect-size           expected size in bytes of the downloaded body
+
+HTTP(S) fetches (not secret-provider schemes, and not cached fetches, which
+have their own conditional-request handling) stream the response straight
+to a temp file next to --output and rename it into place once complete,
+instead of buffering the whole body in memory. --max-download-retries,
+--retry-base-delay, and --retry-max-delay configure a second, inner retry
+loop around just that download, retrying network errors and 5xx/429
+responses (honoring a Retry-After header) with exponential backoff; this
+nests inside the outer --max-attempts loop below, which still covers the
+fetch as a whole (including response validation). If the server sent
+Accept-Ranges: bytes, a retried download resumes from the partial temp file
+with a Range request rather than starting over, validated against the
+stored ETag/Last-Modified via If-Range; the partial file survives even
+across separate "initium fetch" invocations (e.g. after a container
+restart) until it either completes or fails --expect-sha256/--expect-size,
+at which point it's discarded.
+
+--total-budget caps the wall-clock time spent across all attempts and
+sleeps combined, returning early instead of sleeping past the deadline.
+--per-attempt-timeout bounds a single attempt independently of --timeout,
+which bounds the command as a whole.
+
+--cache-dir enables a content-addressable on-disk cache for HTTP(S)
+fetches (not secret-provider schemes), keyed by method, URL, auth, and
+Accept header. A fresh entry is revalidated with a conditional request
+(ETag/If-None-Match, Last-Modified/If-Modified-Since) rather than
+re-downloaded in full; --cache-max-age skips revalidation and forces a
+full refetch once an entry is older than that; --cache-max-bytes evicts
+the least-recently-used entries after each write to stay under that
+total size; --offline serves the cached body instead of failing when the
+request itself cannot reach the origin at all.
+
+--custom-adapter SCHEME=/path/to/bin[,args=arg1;arg2] (repeatable)
+registers an external binary to handle a URL scheme initium has no
+native support for (s3://, gs://, oci://, a torrent magnet link, ...),
+the same custom-transfer-agent protocol git-lfs uses: initium writes one
+newline-delimited JSON {"event":"download","oid":...,"url":...} line to
+the adapter's stdin, then reads {"event":"progress",...} lines (logged,
+not otherwise acted on) until {"event":"complete","path":...} (the
+fetched file, streamed into --output like any other download) or
+{"event":"error","error":...}. A scheme with a registered adapter bypasses
+HTTP(S) handling entirely, including --cache-dir.
+
+--source URL (repeatable) adds a fallback mirror, tried in order after
+--url (and after each other) if the preceding one fails -- including
+exhausting its own --max-download-retries. --host-rule
+HOST=auth-env=ENV,ca-cert=FILE,client-cert=FILE,client-key=FILE,insecure-tls,header=K:V
+(repeatable, comma-separated fields all optional except HOST) overrides
+--auth-env, TLS verification, and request headers for one host (as in the
+URL's host:port) across --url/--source, so different mirrors can use
+different credentials or CA bundles.`,
 		Example: `  # Fetch a config file
   initium fetch --url http://config-service:8080/app.json --output app.json
 
@@ -45,15 +149,53 @@ control, and retries with exponential backoff.`,
   initium fetch --url https://vault:8200/v1/secret/data/app --output secrets.json \
     --auth-env VAULT_TOKEN --insecure-tls
 
+  # Fetch a secret straight from Vault's KV engine (no sidecar needed)
+  initium fetch --url vault://secret/data/myapp/config --output config.json
+
+  # Fetch a single field and flatten the rest into a dotenv file
+  initium fetch --url vault://secret/data/myapp/config#password --output password.txt
+  initium fetch --url vault://secret/data/myapp/config --output app.env --format dotenv
+
+  # Fetch from AWS Secrets Manager via IRSA
+  initium fetch --url aws-sm://prod/db/password --output db-password
+
+  # Fetch a single key from a Kubernetes Secret
+  initium fetch --url k8s-secret://default/app-config/password --output db-password
+
   # Fetch with retries
   initium fetch --url http://api:8080/config --output config.json \
     --max-attempts 10 --initial-delay 2s
 
+  # Resume a large, interrupted download instead of restarting it
+  initium fetch --url http://cdn/build.tar.gz --output build.tar.gz \
+    --expect-sha256 3b9d7a... --max-download-retries 5
+
   # Follow redirects (same-site only by default)
-  initium fetch --url http://cdn/config --output config.json --follow-redirects`,
+  initium fetch --url http://cdn/config --output config.json --follow-redirects
+
+  # Cache to disk and serve the cached copy if the origin is unreachable
+  initium fetch --url http://config-service:8080/app.json --output app.json \
+    --cache-dir /var/cache/initium --cache-max-age 5m --offline
+
+  # Fetch from S3 via a custom transfer adapter binary
+  initium fetch --url s3://my-bucket/build.tar.gz --output build.tar.gz \
+    --custom-adapter "s3=/usr/local/bin/initium-s3-adapter,args=--region;us-east-1"
+
+  # Fall back to a mirror if the primary host is unavailable
+  initium fetch --url https://primary.example.com/build.tar.gz \
+    --source https://mirror.example.com/build.tar.gz --output build.tar.gz
+
+  # Use a client certificate for one mirror host only
+  initium fetch --url https://primary.example.com/app.json \
+    --source https://internal-mirror:8443/app.json --output app.json \
+    --host-rule "internal-mirror:8443=client-cert=/etc/tls/client.crt,client-key=/etc/tls/client.key"`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCtx, rootSpan := tracer.Start(cmd.Context(), "fetch")
+			defer rootSpan.End()
+			cmd.SetContext(rootCtx)
+
 			if jsonLogs {
 				log.SetJSON(true)
 			}
@@ -65,12 +207,40 @@ control, and retries with exponential backoff.`,
 				return fmt.Errorf("--output is required")
 			}
 
+			var adapters *fetch.Manifest
+			if len(customAdapters) > 0 {
+				adapters = fetch.NewManifest()
+				for _, spec := range customAdapters {
+					scheme, bin, adapterArgs, err := parseCustomAdapterFlag(spec)
+					if err != nil {
+						return fmt.Errorf("invalid --custom-adapter %q: %w", spec, err)
+					}
+					adapters.Register(scheme, &fetch.ExecAdapter{AdapterName: scheme, Bin: bin, Args: adapterArgs})
+				}
+			}
+
+			var hostPolicy map[string]fetch.HostRule
+			if len(hostRules) > 0 {
+				hostPolicy = make(map[string]fetch.HostRule, len(hostRules))
+				for _, spec := range hostRules {
+					host, rule, err := parseHostRuleFlag(spec)
+					if err != nil {
+						return fmt.Errorf("invalid --host-rule %q: %w", spec, err)
+					}
+					hostPolicy[host] = rule
+				}
+			}
+
 			retryCfg := retry.Config{
-				MaxAttempts:    maxAttempts,
-				InitialDelay:   initialDelay,
-				MaxDelay:       maxDelay,
-				BackoffFactor:  backoffFactor,
-				JitterFraction: jitterFraction,
+				Name:              "fetch",
+				MaxAttempts:       maxAttempts,
+				InitialDelay:      initialDelay,
+				MaxDelay:          maxDelay,
+				BackoffFactor:     backoffFactor,
+				JitterFraction:    jitterFraction,
+				JitterMode:        retry.JitterMode(jitterMode),
+				TotalBudget:       totalBudget,
+				PerAttemptTimeout: perAttemptTimeout,
 			}
 			if err := retryCfg.Validate(); err != nil {
 				return fmt.Errorf("invalid retry config: %w", err)
@@ -85,6 +255,23 @@ control, and retries with exponential backoff.`,
 				FollowRedirects:        followRedirects,
 				AllowCrossSiteRedirect: allowCrossSiteRedirect,
 				Timeout:                timeout,
+				ExpectStatusRange:      expectStatusRange,
+				ExpectBodyRegex:        expectBodyRegex,
+				ExpectJSONPath:         expectJSONPath,
+				ExpectJSONValue:        expectJSONValue,
+				ExpectSHA256:           expectSHA256,
+				ExpectSize:             expectSize,
+				CacheDir:               cacheDir,
+				CacheMaxAge:            cacheMaxAge,
+				CacheMaxBytes:          cacheMaxBytes,
+				Offline:                offline,
+				Format:                 format,
+				MaxRetries:             maxDownloadRetries,
+				RetryBaseDelay:         retryBaseDelay,
+				RetryMaxDelay:          retryMaxDelay,
+				Adapters:               adapters,
+				Sources:                sources,
+				HostPolicy:             hostPolicy,
 			}
 
 			if err := fetchCfg.Validate(); err != nil {
@@ -96,9 +283,14 @@ control, and retries with exponential backoff.`,
 
 			log.Info("fetching", "url", urlFlag, "output", output)
 
-			result := retry.Do(ctx, retryCfg, func(ctx context.Context, attempt int) error {
+			var fetchResult fetch.Result
+			result := retry.DoWithHooks(ctx, retryCfg, func(ctx context.Context, attempt int) error {
 				log.Debug("fetch attempt", "attempt", fmt.Sprintf("%d", attempt+1))
-				return fetch.Do(ctx, fetchCfg)
+				var err error
+				fetchResult, err = fetch.Do(ctx, fetchCfg)
+				return err
+			}, func(attempt int, err error, nextDelay time.Duration) {
+				log.Debug("retrying fetch", "attempt", fmt.Sprintf("%d", attempt+1), "error", err.Error(), "next_delay", nextDelay.String())
 			})
 
 			if result.Err != nil {
@@ -106,7 +298,21 @@ control, and retries with exponential backoff.`,
 				return fmt.Errorf("fetch %s failed: %w", urlFlag, result.Err)
 			}
 
-			log.Info("fetch completed", "url", urlFlag, "output", output, "attempts", fmt.Sprintf("%d", result.Attempt+1))
+			if fetchResult.CacheOffline {
+				log.Warn("fetch served from cache, origin unreachable", "url", urlFlag, "output", output)
+			}
+			if leaseDuration, ok := fetchResult.SecretMeta["lease_duration"]; ok {
+				log.Info("vault lease", "lease_duration_seconds", leaseDuration)
+			}
+			for i, a := range fetchResult.Attempts {
+				if a.Err != "" {
+					log.Debug("source attempt failed", "attempt", fmt.Sprintf("%d", i+1), "host", a.Host, "status", fmt.Sprintf("%d", a.StatusCode), "error", a.Err)
+				} else {
+					log.Debug("source attempt succeeded", "attempt", fmt.Sprintf("%d", i+1), "host", a.Host, "status", fmt.Sprintf("%d", a.StatusCode))
+				}
+			}
+
+			log.Info("fetch completed", "url", urlFlag, "output", output, "attempts", fmt.Sprintf("%d", result.Attempt+1), "elapsed", result.Elapsed.String())
 			return nil
 		},
 	}
@@ -125,6 +331,100 @@ control, and retries with exponential backoff.`,
 	cmd.Flags().Float64Var(&backoffFactor, "backoff-factor", 2.0, "Backoff multiplier")
 	cmd.Flags().Float64Var(&jitterFraction, "jitter", 0.1, "Jitter fraction (0.0-1.0)")
 	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	cmd.Flags().StringVar(&expectStatusRange, "expect-status-range", "", "Expected HTTP status range, e.g. 200-299 (default 200-299)")
+	cmd.Flags().StringVar(&expectBodyRegex, "expect-body-regex", "", "Regex that must match the response body")
+	cmd.Flags().StringVar(&expectJSONPath, "expect-json-path", "", "JSONPath into the decoded response body, e.g. status.ready")
+	cmd.Flags().StringVar(&expectJSONValue, "expect-json-value", "", "Value --expect-json-path must stringify to (required with --expect-json-path)")
+	cmd.Flags().StringVar(&expectSHA256, "expect-sha256", "", "Expected sha256 hex digest of the downloaded body")
+	cmd.Flags().Int64Var(&expectSize, "expect-size", 0, "Expected size in bytes of the downloaded body; 0 disables the check")
+	cmd.Flags().StringVar(&jitterMode, "jitter-mode", "", `Backoff jitter strategy: "" (additive, default), "full", or "decorrelated"`)
+	cmd.Flags().DurationVar(&totalBudget, "total-budget", 0, "Cap wall-clock time across all attempts and sleeps; 0 disables")
+	cmd.Flags().DurationVar(&perAttemptTimeout, "per-attempt-timeout", 0, "Timeout applied to each individual fetch attempt; 0 disables")
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Directory for a content-addressable HTTP(S) fetch cache; empty disables caching")
+	cmd.Flags().DurationVar(&cacheMaxAge, "cache-max-age", 0, "Force a full refetch once a cache entry is older than this; 0 always revalidates (requires --cache-dir)")
+	cmd.Flags().Int64Var(&cacheMaxBytes, "cache-max-bytes", 0, "Evict least-recently-used cache entries to stay under this total size; 0 disables eviction (requires --cache-dir)")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Serve the cached body instead of failing when the origin is unreachable (requires --cache-dir)")
+	cmd.Flags().StringVar(&format, "format", "", "How to write a secret-provider body: raw (default), json, or dotenv")
+	cmd.Flags().IntVar(&maxDownloadRetries, "max-download-retries", 0, "Retries for the download itself (network errors, 5xx, 429), nested inside --max-attempts; 0 disables")
+	cmd.Flags().DurationVar(&retryBaseDelay, "retry-base-delay", 500*time.Millisecond, "Initial backoff between download retries (see --max-download-retries)")
+	cmd.Flags().DurationVar(&retryMaxDelay, "retry-max-delay", 30*time.Second, "Maximum backoff between download retries (see --max-download-retries)")
+	cmd.Flags().StringArrayVar(&customAdapters, "custom-adapter", nil, "Register a transfer adapter for a URL scheme: SCHEME=/path/to/bin[,args=arg1;arg2] (repeatable)")
+	cmd.Flags().StringArrayVar(&sources, "source", nil, "Fallback mirror URL, tried in order after --url and earlier --source values (repeatable)")
+	cmd.Flags().StringArrayVar(&hostRules, "host-rule", nil, "Per-host policy override: HOST=auth-env=ENV,ca-cert=FILE,client-cert=FILE,client-key=FILE,insecure-tls,header=K:V (repeatable)")
 
 	return cmd
 }
+
+// parseCustomAdapterFlag parses a --custom-adapter value of the form
+// "SCHEME=/path/to/bin[,args=arg1;arg2]" into the scheme to register, the
+// adapter binary, and its arguments (";"-separated, since individual args
+// may themselves need commas).
+func parseCustomAdapterFlag(spec string) (scheme, bin string, args []string, err error) {
+	parts := strings.SplitN(spec, ",", 2)
+
+	schemeBin := strings.SplitN(parts[0], "=", 2)
+	if len(schemeBin) != 2 || schemeBin[0] == "" || schemeBin[1] == "" {
+		return "", "", nil, fmt.Errorf("expected SCHEME=/path/to/bin[,args=...], got %q", spec)
+	}
+	scheme, bin = schemeBin[0], schemeBin[1]
+
+	if len(parts) == 2 {
+		kv := strings.SplitN(parts[1], "=", 2)
+		if len(kv) != 2 || kv[0] != "args" {
+			return "", "", nil, fmt.Errorf("expected args=... after SCHEME=/path/to/bin, got %q", parts[1])
+		}
+		if kv[1] != "" {
+			args = strings.Split(kv[1], ";")
+		}
+	}
+
+	return scheme, bin, args, nil
+}
+
+// parseHostRuleFlag parses a --host-rule value of the form
+// "HOST=field=value,field=value,..." into the host it applies to and the
+// HostRule it builds. Recognized fields: auth-env, ca-cert, client-cert,
+// client-key (each a single value), insecure-tls (a bare flag, no value),
+// and header (repeatable, "Key:Value", collected into HostRule.Headers).
+func parseHostRuleFlag(spec string) (string, fetch.HostRule, error) {
+	parts := strings.SplitN(spec, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fetch.HostRule{}, fmt.Errorf("expected HOST=field=value,..., got %q", spec)
+	}
+	host := parts[0]
+
+	var rule fetch.HostRule
+	for _, field := range strings.Split(parts[1], ",") {
+		if field == "insecure-tls" {
+			rule.InsecureTLS = true
+			continue
+		}
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			return "", fetch.HostRule{}, fmt.Errorf("expected field=value, got %q", field)
+		}
+		switch kv[0] {
+		case "auth-env":
+			rule.AuthEnv = kv[1]
+		case "ca-cert":
+			rule.CACertFile = kv[1]
+		case "client-cert":
+			rule.ClientCertFile = kv[1]
+		case "client-key":
+			rule.ClientKeyFile = kv[1]
+		case "header":
+			hk, hv, ok := strings.Cut(kv[1], ":")
+			if !ok {
+				return "", fetch.HostRule{}, fmt.Errorf("expected header=Key:Value, got %q", field)
+			}
+			if rule.Headers == nil {
+				rule.Headers = make(map[string]string)
+			}
+			rule.Headers[hk] = hv
+		default:
+			return "", fetch.HostRule{}, fmt.Errorf("unknown --host-rule field %q", kv[0])
+		}
+	}
+
+	return host, rule, nil
+}
@@ -223,3 +223,185 @@ func TestFetchCmdCrossSiteWithoutFollowRedirects(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestFetchCmdTotalBudgetExhausted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+
+	lg := logging.Default()
+	c := NewFetchCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{
+		"--url", srv.URL,
+		"--output", "out.txt",
+		"--workdir", workdir,
+		"--max-attempts", "10",
+		"--initial-delay", "50ms",
+		"--max-delay", "50ms",
+		"--total-budget", "10ms",
+	})
+
+	err := c.Execute()
+	if err == nil {
+		t.Fatal("expected error when total budget is exhausted")
+	}
+}
+
+func TestFetchCmdPerAttemptTimeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	// close(block) must run before srv.Close(): Close waits for the
+	// in-flight handler goroutine to return, which only happens once
+	// block is closed. Deferred in this order so LIFO unwinding runs
+	// close(block) first.
+	defer srv.Close()
+	defer close(block)
+
+	workdir := t.TempDir()
+
+	lg := logging.Default()
+	c := NewFetchCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{
+		"--url", srv.URL,
+		"--output", "out.txt",
+		"--workdir", workdir,
+		"--max-attempts", "1",
+		"--timeout", "1s",
+		"--per-attempt-timeout", "10ms",
+	})
+
+	err := c.Execute()
+	if err == nil {
+		t.Fatal("expected error from per-attempt timeout")
+	}
+}
+
+func TestFetchCmdExpectSizeMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+
+	lg := logging.Default()
+	c := NewFetchCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{
+		"--url", srv.URL,
+		"--output", "out.txt",
+		"--workdir", workdir,
+		"--max-attempts", "1",
+		"--expect-size", "99",
+	})
+
+	err := c.Execute()
+	if err == nil {
+		t.Fatal("expected error for --expect-size mismatch")
+	}
+	if !strings.Contains(err.Error(), "--expect-size mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFetchCmdMaxDownloadRetriesRecoversFromTransientError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+
+	lg := logging.Default()
+	c := NewFetchCmd(lg)
+	c.SetArgs([]string{
+		"--url", srv.URL,
+		"--output", "out.txt",
+		"--workdir", workdir,
+		"--max-attempts", "1",
+		"--max-download-retries", "2",
+		"--retry-base-delay", "10ms",
+	})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("expected download retry to recover, got: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests (1 failure + 1 success), got %d", requests)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.txt"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != "ok" {
+		t.Fatalf("expected 'ok', got %q", content)
+	}
+}
+
+func TestParseCustomAdapterFlag(t *testing.T) {
+	scheme, bin, args, err := parseCustomAdapterFlag("s3=/usr/local/bin/s3-adapter,args=--region;us-east-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scheme != "s3" || bin != "/usr/local/bin/s3-adapter" {
+		t.Fatalf("unexpected scheme/bin: %q/%q", scheme, bin)
+	}
+	if len(args) != 2 || args[0] != "--region" || args[1] != "us-east-1" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestParseCustomAdapterFlagNoArgs(t *testing.T) {
+	scheme, bin, args, err := parseCustomAdapterFlag("gs=/usr/local/bin/gs-adapter")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if scheme != "gs" || bin != "/usr/local/bin/gs-adapter" {
+		t.Fatalf("unexpected scheme/bin: %q/%q", scheme, bin)
+	}
+	if args != nil {
+		t.Fatalf("expected nil args, got %v", args)
+	}
+}
+
+func TestParseCustomAdapterFlagInvalid(t *testing.T) {
+	if _, _, _, err := parseCustomAdapterFlag("not-valid"); err == nil {
+		t.Fatal("expected error for missing =")
+	}
+}
+
+func TestFetchCmdUnknownSchemeWithoutAdapter(t *testing.T) {
+	lg := logging.Default()
+	c := NewFetchCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{
+		"--url", "s3://my-bucket/object",
+		"--output", "out.txt",
+		"--max-attempts", "1",
+	})
+
+	err := c.Execute()
+	if err == nil {
+		t.Fatal("expected error fetching an unregistered scheme")
+	}
+}
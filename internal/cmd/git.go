@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/cobra"
+
+	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/safety"
+)
+
+// gitSourceFlags holds the --from-git flag family shared by exec, seed, and
+// migrate, letting those commands run a script checked out from a Git repo
+// instead of one baked into the image.
+type gitSourceFlags struct {
+	fromGit   string
+	gitRef    string
+	gitSubdir string
+	gitSSHKey string
+	gitToken  string
+	timeout   time.Duration
+}
+
+// registerGitSourceFlags adds the --from-git flag family to cmd and returns
+// the bound values.
+func registerGitSourceFlags(cmd *cobra.Command) *gitSourceFlags {
+	f := &gitSourceFlags{}
+	cmd.Flags().StringVar(&f.fromGit, "from-git", "", "Clone a Git repository and use it (or --git-subdir within it) as the effective workdir instead of one baked into the image")
+	cmd.Flags().StringVar(&f.gitRef, "git-ref", "", "Branch, tag, or commit SHA to check out (default: the repo's default branch)")
+	cmd.Flags().StringVar(&f.gitSubdir, "git-subdir", "", "Subdirectory within the cloned repo to use as the effective workdir")
+	cmd.Flags().StringVar(&f.gitSSHKey, "git-ssh-key", "", "Path to an SSH private key for git@/ssh:// clone URLs")
+	cmd.Flags().StringVar(&f.gitToken, "git-token", "", "Access token for HTTPS clone URLs, sent as the HTTP Basic password")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 5*time.Minute, "Timeout for the --from-git clone")
+	return f
+}
+
+// resolveWorkdir clones f.fromGit (if set) into a temp directory and
+// returns the directory callers should use as the effective workdir,
+// honoring f.gitSubdir, plus a cleanup func that removes the clone and must
+// be called once the caller is done with it. If f.fromGit is empty, it
+// returns fallbackWorkdir unchanged and a no-op cleanup.
+func (f *gitSourceFlags) resolveWorkdir(ctx context.Context, log *logging.Logger, fallbackWorkdir string) (dir string, cleanup func(), err error) {
+	if f.fromGit == "" {
+		return fallbackWorkdir, func() {}, nil
+	}
+
+	cloneDir, err := os.MkdirTemp("", "initium-git-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp clone directory: %w", err)
+	}
+	cleanup = func() {
+		if err := os.RemoveAll(cloneDir); err != nil {
+			log.Warn("removing git clone temp directory failed", "dir", cloneDir, "error", err.Error())
+		}
+	}
+
+	cloneCtx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	log.Info("cloning git source", "url", f.fromGit, "ref", f.gitRef)
+	if err := f.clone(cloneCtx, cloneDir); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	workdir := cloneDir
+	if f.gitSubdir != "" {
+		subdir, err := safety.ValidateFilePath(cloneDir, f.gitSubdir)
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("invalid --git-subdir: %w", err)
+		}
+		workdir = subdir
+	}
+
+	log.Info("git source ready", "workdir", workdir)
+	return workdir, cleanup, nil
+}
+
+// clone shallow-clones f.fromGit into dir, resolving f.gitRef as a branch,
+// then a tag, unless it's a full commit SHA. A shallow clone can't fetch an
+// arbitrary historical commit, so a pinned SHA falls back to a full clone
+// and an explicit checkout, then verifies the checked-out HEAD matches the
+// requested SHA.
+func (f *gitSourceFlags) clone(ctx context.Context, dir string) error {
+	auth, err := f.auth()
+	if err != nil {
+		return err
+	}
+
+	if isFullSHA(f.gitRef) {
+		repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+			URL:  f.fromGit,
+			Auth: auth,
+		})
+		if err != nil {
+			return fmt.Errorf("cloning %s: %w", f.fromGit, err)
+		}
+		return checkoutSHA(repo, f.gitRef)
+	}
+
+	opts := &git.CloneOptions{
+		URL:   f.fromGit,
+		Auth:  auth,
+		Depth: 1,
+	}
+	if f.gitRef == "" {
+		if _, err := git.PlainCloneContext(ctx, dir, false, opts); err != nil {
+			return fmt.Errorf("cloning %s: %w", f.fromGit, err)
+		}
+		return nil
+	}
+
+	opts.SingleBranch = true
+	opts.ReferenceName = plumbing.NewBranchReferenceName(f.gitRef)
+	if _, err := git.PlainCloneContext(ctx, dir, false, opts); err == nil {
+		return nil
+	}
+
+	// f.gitRef might name a tag rather than a branch.
+	opts.ReferenceName = plumbing.NewTagReferenceName(f.gitRef)
+	if _, err := git.PlainCloneContext(ctx, dir, false, opts); err != nil {
+		return fmt.Errorf("cloning %s at ref %q: %w", f.fromGit, f.gitRef, err)
+	}
+	return nil
+}
+
+// auth builds the transport.AuthMethod for f.fromGit from whichever of
+// --git-ssh-key / --git-token is set; neither set means the clone relies on
+// the URL alone (a public HTTPS repo, or ambient SSH agent credentials).
+func (f *gitSourceFlags) auth() (transport.AuthMethod, error) {
+	switch {
+	case f.gitSSHKey != "" && f.gitToken != "":
+		return nil, fmt.Errorf("--git-ssh-key and --git-token are mutually exclusive")
+	case f.gitSSHKey != "":
+		auth, err := gitssh.NewPublicKeysFromFile("git", f.gitSSHKey, "")
+		if err != nil {
+			return nil, fmt.Errorf("loading --git-ssh-key %s: %w", f.gitSSHKey, err)
+		}
+		return auth, nil
+	case f.gitToken != "":
+		return &githttp.BasicAuth{Username: "x-access-token", Password: f.gitToken}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// isFullSHA reports whether ref looks like a full 40-character hex commit
+// SHA rather than a branch or tag name.
+func isFullSHA(ref string) bool {
+	if len(ref) != 40 {
+		return false
+	}
+	for _, r := range ref {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// checkoutSHA checks out the exact commit hash and verifies that's what
+// HEAD ends up pointing at, so a shallow-clone edge case or a mutated repo
+// can't silently leave a different commit checked out than requested.
+func checkoutSHA(repo *git.Repository, sha string) error {
+	hash := plumbing.NewHash(sha)
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("opening worktree: %w", err)
+	}
+	if err := w.Checkout(&git.CheckoutOptions{Hash: hash}); err != nil {
+		return fmt.Errorf("checking out commit %s: %w", sha, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("reading HEAD after checkout: %w", err)
+	}
+	if head.Hash() != hash {
+		return fmt.Errorf("checked out commit %s does not match requested --git-ref %s", head.Hash(), sha)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+func TestIsFullSHA(t *testing.T) {
+	tests := map[string]bool{
+		"":       false,
+		"main":   false,
+		"v1.2.0": false,
+		"abc123": false,
+		"ABCDEF0123456789ABCDEF0123456789ABCDEF01":  false, // uppercase hex not accepted
+		"abcdef0123456789abcdef0123456789abcdef01":  true,
+		"abcdef0123456789abcdef0123456789abcdef0":   false, // 39 chars
+		"abcdef0123456789abcdef0123456789abcdef012": false, // 41 chars
+		"ghijkl0123456789abcdef0123456789abcdef01":  false, // non-hex chars
+	}
+	for ref, want := range tests {
+		if got := isFullSHA(ref); got != want {
+			t.Errorf("isFullSHA(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestGitSourceFlagsAuthMutuallyExclusive(t *testing.T) {
+	f := &gitSourceFlags{gitSSHKey: "/tmp/key", gitToken: "tok"}
+	if _, err := f.auth(); err == nil {
+		t.Fatal("expected error when both --git-ssh-key and --git-token are set")
+	}
+}
+
+func TestGitSourceFlagsAuthToken(t *testing.T) {
+	f := &gitSourceFlags{gitToken: "tok"}
+	auth, err := f.auth()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth == nil {
+		t.Fatal("expected non-nil auth for --git-token")
+	}
+}
+
+func TestGitSourceFlagsAuthNone(t *testing.T) {
+	f := &gitSourceFlags{}
+	auth, err := f.auth()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != nil {
+		t.Fatalf("expected nil auth when neither flag is set, got %v", auth)
+	}
+}
+
+func TestResolveWorkdirNoOpWithoutFromGit(t *testing.T) {
+	f := &gitSourceFlags{}
+	log := logging.Default()
+
+	dir, cleanup, err := f.resolveWorkdir(context.Background(), log, "/some/fallback")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if dir != "/some/fallback" {
+		t.Fatalf("expected fallback workdir unchanged, got %q", dir)
+	}
+}
+
+// initTestRepo creates a local git repository with one commit on "main" and
+// a second commit tagged "v1.0.0", returning the repo path and both commit
+// SHAs, so clone logic can be exercised against a real repo without network
+// access.
+func initTestRepo(t *testing.T) (repoPath, firstSHA, secondSHA string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		c.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		var out bytes.Buffer
+		c.Stdout = &out
+		c.Stderr = &out
+		if err := c.Run(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out.String())
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	run("init", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "setup.sh"), []byte("#!/bin/sh\necho first\n"), 0o644); err != nil {
+		t.Fatalf("writing fixture file: %v", err)
+	}
+	run("add", ".")
+	run("commit", "-m", "first commit")
+	firstSHA = run("rev-parse", "HEAD")
+
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("creating subdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "seed.sql"), []byte("-- seed\n"), 0o644); err != nil {
+		t.Fatalf("writing fixture file: %v", err)
+	}
+	run("add", ".")
+	run("commit", "-m", "second commit")
+	secondSHA = run("rev-parse", "HEAD")
+	run("tag", "v1.0.0")
+
+	return dir, firstSHA, secondSHA
+}
+
+func TestResolveWorkdirClonesDefaultBranch(t *testing.T) {
+	repoPath, _, _ := initTestRepo(t)
+	f := &gitSourceFlags{fromGit: repoPath, timeout: 30 * time.Second}
+	log := logging.Default()
+
+	dir, cleanup, err := f.resolveWorkdir(context.Background(), log, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, "sub", "seed.sql")); err != nil {
+		t.Fatalf("expected cloned file to exist: %v", err)
+	}
+}
+
+func TestResolveWorkdirChecksOutTag(t *testing.T) {
+	repoPath, firstSHA, _ := initTestRepo(t)
+	f := &gitSourceFlags{fromGit: repoPath, gitRef: "v1.0.0", timeout: 30 * time.Second}
+	log := logging.Default()
+
+	dir, cleanup, err := f.resolveWorkdir(context.Background(), log, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	_ = firstSHA
+	if _, err := os.Stat(filepath.Join(dir, "sub", "seed.sql")); err != nil {
+		t.Fatalf("expected file from tagged commit to exist: %v", err)
+	}
+}
+
+func TestResolveWorkdirChecksOutFullSHA(t *testing.T) {
+	repoPath, firstSHA, _ := initTestRepo(t)
+	f := &gitSourceFlags{fromGit: repoPath, gitRef: firstSHA, timeout: 30 * time.Second}
+	log := logging.Default()
+
+	dir, cleanup, err := f.resolveWorkdir(context.Background(), log, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, "setup.sh")); err != nil {
+		t.Fatalf("expected file from first commit to exist: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub", "seed.sql")); err == nil {
+		t.Fatal("expected file added after the pinned commit to be absent")
+	}
+}
+
+func TestResolveWorkdirGitSubdir(t *testing.T) {
+	repoPath, _, _ := initTestRepo(t)
+	f := &gitSourceFlags{fromGit: repoPath, gitSubdir: "sub", timeout: 30 * time.Second}
+	log := logging.Default()
+
+	dir, cleanup, err := f.resolveWorkdir(context.Background(), log, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, "seed.sql")); err != nil {
+		t.Fatalf("expected --git-subdir to resolve into the cloned subdirectory: %v", err)
+	}
+}
+
+func TestResolveWorkdirGitSubdirRejectsTraversal(t *testing.T) {
+	repoPath, _, _ := initTestRepo(t)
+	f := &gitSourceFlags{fromGit: repoPath, gitSubdir: "../../etc", timeout: 30 * time.Second}
+	log := logging.Default()
+
+	if _, _, err := f.resolveWorkdir(context.Background(), log, ""); err == nil {
+		t.Fatal("expected error for --git-subdir path traversal")
+	}
+}
+
+func TestResolveWorkdirCleansUpOnCloneFailure(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	f := &gitSourceFlags{fromGit: "/nonexistent/repo/path", timeout: 5 * time.Second}
+	log := logging.Default()
+
+	if _, _, err := f.resolveWorkdir(context.Background(), log, ""); err == nil {
+		t.Fatal("expected error cloning a nonexistent repo")
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		t.Fatalf("reading temp dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected temp clone directory to be removed after clone failure, found: %v", entries)
+	}
+}
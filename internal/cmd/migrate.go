@@ -1,14 +1,14 @@
 package cmd
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"os"
-	"os/exec"
-	"sync"
-	"syscall"
+	"strings"
+	"time"
 
+	"github.com/kitstream/initium/internal/lock"
 	"github.com/kitstream/initium/internal/logging"
 	"github.com/kitstream/initium/internal/safety"
 	"github.com/spf13/cobra"
@@ -16,10 +16,18 @@ import (
 
 func NewMigrateCmd(log *logging.Logger) *cobra.Command {
 	var (
-		workdir  string
-		lockFile string
-		jsonLogs bool
+		workdir         string
+		lockFile        string
+		lockBackend     string
+		lockKey         string
+		lockTTL         time.Duration
+		lockWait        time.Duration
+		jsonLogs        bool
+		shutdownSignal  string
+		shutdownTimeout time.Duration
+		maxLineBytes    int
 	)
+	var gitFlags *gitSourceFlags
 
 	cmd := &cobra.Command{
 		Use:   "migrate -- COMMAND [ARGS...]",
@@ -32,7 +40,52 @@ initium flags from the migration command and its arguments.
 
 If --lock-file is set, the migration is skipped when the lock file already
 exists inside --workdir. On successful completion the lock file is created
-so subsequent runs become no-ops.`,
+so subsequent runs become no-ops. --lock-file alone does not prevent two
+replicas that start at the same instant from both running the migration;
+see --lock-backend below for that.
+
+If --lock-backend is set, initium acquires a distributed lock before
+running the command and releases it once the command finishes (or the
+attempt fails), serializing concurrent migration attempts across replicas
+instead of just detecting completion after the fact. Supported schemes:
+file://, postgres://, redis://, etcd://, and k8s-lease://.
+--lock-key selects what the lock is keyed on (default: the migration
+command and its arguments). --lock-ttl bounds how long a backend whose
+lock has a server-enforced lifetime (redis, etcd, k8s-lease) holds it without a
+live holder renewing it; it's ignored by backends where the lock's
+lifetime is tied to a session or file instead (file, postgres). The lock
+is released even if initium is asked to shut down mid-migration, since
+release happens once the signal-forwarding shutdown path below returns
+control.
+
+By default, failing to acquire --lock-backend waits indefinitely for the
+current holder to release it. --lock-wait bounds that wait: if the lock
+is still held when it elapses, initium logs "another instance holds the
+lock" and exits 0 rather than erroring, on the assumption that whoever
+holds it is already running the same migration.
+
+If --from-git is set, that repository is shallow-cloned into a temp
+directory (cleaned up on exit) and used as the effective --workdir (and
+the migration command's working directory) instead, so migration scripts
+can live in version control rather than being baked into the image.
+--git-ref pins a branch, tag, or commit SHA; --git-subdir selects a
+directory within the clone; --git-ssh-key and --git-token authenticate to
+private repos over SSH and HTTPS respectively; --timeout bounds how long
+the clone itself is allowed to take.
+
+The migration command runs in its own process group. If initium itself
+receives SIGTERM, SIGINT, or SIGHUP (e.g. a Kubernetes pod eviction),
+--shutdown-signal (default SIGTERM) is forwarded to that process group so
+the migration tool gets a chance to exit cleanly; if it hasn't exited
+within --shutdown-timeout (default 30s), it is killed with SIGKILL.
+
+A migration tool line starting with '{' that parses as JSON has its
+fields forwarded directly into the log output instead of being wrapped
+whole as the message; severity is inferred from a JSON "level" field, an
+ERROR/WARN substring in plain text, or stderr defaulting to WARN, so a
+failing migration surfaces at the right level. --max-line-bytes (default
+4MiB) caps how much of a single line is buffered before it's truncated
+with a warning rather than silently dropped.`,
 		Example: `  # Run a flyway migration
   initium migrate -- flyway migrate
 
@@ -40,10 +93,25 @@ so subsequent runs become no-ops.`,
   initium migrate --json -- /app/migrate -path /migrations up
 
   # Idempotent: skip if already migrated
-  initium migrate --lock-file .migrated --workdir /work -- /app/migrate up`,
+  initium migrate --lock-file .migrated --workdir /work -- /app/migrate up
+
+  # Serialize concurrent replicas with a Postgres advisory lock
+  initium migrate --lock-backend postgres://user:pass@db:5432/app -- /app/migrate up
+
+  # Serialize replicas in-cluster with a Lease, giving up after 2 minutes
+  initium migrate --lock-backend k8s-lease://default --lock-wait 2m -- /app/migrate up
+
+  # Run migrations checked out from a Git repo
+  initium migrate --from-git https://github.com/acme/migrations.git --git-ref main -- flyway migrate
+
+  # Give the migration tool longer to wind down on shutdown
+  initium migrate --shutdown-timeout 2m -- /app/migrate up`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rootSpan := tracer.Start(cmd.Context(), "migrate")
+			defer rootSpan.End()
+
 			if jsonLogs {
 				log.SetJSON(true)
 			}
@@ -52,6 +120,18 @@ so subsequent runs become no-ops.`,
 				return fmt.Errorf("migration command is required after \"--\"")
 			}
 
+			sig, err := parseShutdownSignal(shutdownSignal)
+			if err != nil {
+				return err
+			}
+
+			effectiveWorkdir, cleanup, err := gitFlags.resolveWorkdir(cmd.Context(), log, workdir)
+			if err != nil {
+				return fmt.Errorf("resolving --from-git source: %w", err)
+			}
+			defer cleanup()
+			workdir = effectiveWorkdir
+
 			if lockFile != "" {
 				lockPath, err := safety.ValidateFilePath(workdir, lockFile)
 				if err != nil {
@@ -64,9 +144,52 @@ so subsequent runs become no-ops.`,
 				}
 			}
 
+			if lockBackend != "" {
+				key := lockKey
+				if key == "" {
+					key = strings.Join(args, " ")
+				}
+
+				backend, err := lock.Open(lockBackend)
+				if err != nil {
+					return fmt.Errorf("opening --lock-backend: %w", err)
+				}
+
+				acquireCtx := cmd.Context()
+				if lockWait > 0 {
+					var cancel context.CancelFunc
+					acquireCtx, cancel = context.WithTimeout(acquireCtx, lockWait)
+					defer cancel()
+				}
+
+				log.Info("acquiring distributed lock", "lock-backend", lockBackend, "lock-key", key)
+				lease, err := backend.Acquire(acquireCtx, key, lockTTL)
+				if err != nil {
+					if lockWait > 0 && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+						log.Info("another instance holds the lock", "lock-key", key, "lock-wait", lockWait.String())
+						return nil
+					}
+					return fmt.Errorf("acquiring distributed lock: %w", err)
+				}
+				log.Info("distributed lock acquired", "lock-key", key)
+				defer func() {
+					if err := lease.Release(context.Background()); err != nil {
+						log.Warn("releasing distributed lock failed", "error", err.Error())
+					}
+				}()
+			}
+
 			log.Info("starting migration", "command", args[0])
 
-			exitCode, err := runCommand(log, args)
+			// workdir only changes the migration command's own working
+			// directory when it came from --from-git; otherwise --workdir
+			// keeps its historical meaning of "where lock files live",
+			// leaving the command's cwd untouched for backward compatibility.
+			runDir := ""
+			if gitFlags.fromGit != "" {
+				runDir = workdir
+			}
+			exitCode, err := runCommandInDir(log, args, runDir, sig, shutdownTimeout, maxLineBytes)
 			if err != nil {
 				return fmt.Errorf("migration failed: %w", err)
 			}
@@ -98,99 +221,15 @@ so subsequent runs become no-ops.`,
 
 	cmd.Flags().StringVar(&workdir, "workdir", "/work", "Working directory for file operations")
 	cmd.Flags().StringVar(&lockFile, "lock-file", "", "Skip migration if this file exists in workdir (idempotency)")
+	cmd.Flags().StringVar(&lockBackend, "lock-backend", "", "URL of a distributed lock backend to serialize concurrent migration runs across replicas: file://, postgres://, redis://, etcd://, or k8s-lease://")
+	cmd.Flags().StringVar(&lockKey, "lock-key", "", "Key to lock on when --lock-backend is set (default: the migration command and its arguments)")
+	cmd.Flags().DurationVar(&lockTTL, "lock-ttl", 30*time.Second, "How long a --lock-backend with a server-enforced lock lifetime (redis, etcd, k8s-lease) holds the lock without a live holder renewing it; ignored by file and postgres")
+	cmd.Flags().DurationVar(&lockWait, "lock-wait", 0, "How long to wait for a held --lock-backend lock before giving up and exiting 0; 0 waits indefinitely")
 	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	cmd.Flags().StringVar(&shutdownSignal, "shutdown-signal", defaultShutdownSignal, "Signal to forward to the migration command's process group on SIGTERM/SIGINT/SIGHUP: SIGTERM, SIGINT, SIGHUP, or SIGQUIT")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "How long to wait for the migration command to exit after forwarding --shutdown-signal before sending SIGKILL")
+	cmd.Flags().IntVar(&maxLineBytes, "max-line-bytes", defaultMaxLineBytes, "Maximum bytes of a single output line to buffer before truncating it with a warning")
+	gitFlags = registerGitSourceFlags(cmd)
 
 	return cmd
 }
-
-func runCommand(log *logging.Logger, args []string) (int, error) {
-	c := newExecCommand(args[0], args[1:]...)
-	return executeAndStream(log, c)
-}
-
-func newExecCommand(name string, args ...string) *exec.Cmd {
-	c := exec.Command(name, args...)
-	c.Stdin = nil
-	return c
-}
-
-func executeAndStream(log *logging.Logger, c *exec.Cmd) (int, error) {
-	stdoutPipe, err := c.StdoutPipe()
-	if err != nil {
-		return -1, fmt.Errorf("creating stdout pipe: %w", err)
-	}
-
-	stderrPipe, err := c.StderrPipe()
-	if err != nil {
-		return -1, fmt.Errorf("creating stderr pipe: %w", err)
-	}
-
-	if err := c.Start(); err != nil {
-		return -1, fmt.Errorf("starting command %q: %w", c.Path, err)
-	}
-
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		streamLines(log, stdoutPipe, "stdout")
-	}()
-
-	go func() {
-		defer wg.Done()
-		streamLines(log, stderrPipe, "stderr")
-	}()
-
-	wg.Wait()
-
-	err = c.Wait()
-	if err == nil {
-		return 0, nil
-	}
-
-	var exitErr *exec.ExitError
-	if ok := asExitError(err, &exitErr); ok {
-		return exitErr.ExitCode(), nil
-	}
-
-	return -1, err
-}
-
-func asExitError(err error, target **exec.ExitError) bool {
-	if e, ok := err.(*exec.ExitError); ok {
-		*target = e
-		return true
-	}
-	return false
-}
-
-func streamLines(log *logging.Logger, r io.Reader, stream string) {
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		log.Info(scanner.Text(), "stream", stream)
-	}
-}
-
-// ExitCodeFromError extracts the exit code from a command error.
-// Used by callers that need to propagate exit codes (e.g., os.Exit).
-func ExitCodeFromError(err error) int {
-	if err == nil {
-		return 0
-	}
-
-	// Check if the error message contains an exit code pattern
-	var exitCode int
-	if n, _ := fmt.Sscanf(err.Error(), "migration exited with code %d", &exitCode); n == 1 {
-		return exitCode
-	}
-
-	// Check for underlying process exit status
-	if exitErr, ok := err.(*exec.ExitError); ok {
-		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
-			return status.ExitStatus()
-		}
-	}
-
-	return 1
-}
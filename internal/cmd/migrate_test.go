@@ -7,7 +7,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/kitstream/initium/internal/logging"
 )
@@ -227,6 +229,108 @@ func TestMigrateCmdLockFilePathTraversal(t *testing.T) {
 	}
 }
 
+func TestMigrateCmdLockBackendAcquiresAndReleases(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	lockDir := t.TempDir()
+
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+	cmd := NewMigrateCmd(log)
+	cmd.SetArgs([]string{
+		"--lock-backend", "file://" + lockDir,
+		"--lock-key", "test-migration",
+		"--",
+		"echo", "migrating",
+	})
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "distributed lock acquired") {
+		t.Fatalf("expected lock acquired message, got: %s", output)
+	}
+
+	entries, err := os.ReadDir(lockDir)
+	if err != nil {
+		t.Fatalf("reading lock dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected lock file to be removed after release, found: %v", entries)
+	}
+}
+
+func TestMigrateCmdLockWaitTimesOutAndExitsZero(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	lockDir := t.TempDir()
+
+	holderDone := make(chan struct{})
+	go func() {
+		defer close(holderDone)
+		holderLog := logging.New(&bytes.Buffer{}, false, logging.LevelInfo)
+		holder := NewMigrateCmd(holderLog)
+		holder.SetArgs([]string{
+			"--lock-backend", "file://" + lockDir,
+			"--lock-key", "test-migration",
+			"--",
+			"sleep", "1",
+		})
+		_ = holder.Execute()
+	}()
+
+	// Give the holder a head start so it's guaranteed to win the race for
+	// the lock before the contender below attempts to acquire it.
+	time.Sleep(200 * time.Millisecond)
+
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+	cmd := NewMigrateCmd(log)
+	cmd.SetArgs([]string{
+		"--lock-backend", "file://" + lockDir,
+		"--lock-key", "test-migration",
+		"--lock-wait", "300ms",
+		"--",
+		"echo", "migrating",
+	})
+
+	err := cmd.Execute()
+	if err != nil {
+		t.Fatalf("expected exit 0 when lock is held by another instance, got: %v", err)
+	}
+	if !strings.Contains(buf.String(), "another instance holds the lock") {
+		t.Fatalf("expected lock-wait timeout message, got: %s", buf.String())
+	}
+
+	<-holderDone
+}
+
+func TestMigrateCmdLockBackendInvalidScheme(t *testing.T) {
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+	cmd := NewMigrateCmd(log)
+	cmd.SetArgs([]string{
+		"--lock-backend", "memcached://localhost",
+		"--",
+		"echo", "migrating",
+	})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for unsupported lock backend scheme")
+	}
+	if !strings.Contains(err.Error(), "opening --lock-backend") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestMigrateCmdCommandNotFound(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("skipping on windows")
@@ -273,7 +377,7 @@ func TestRunCommandSuccess(t *testing.T) {
 	var buf bytes.Buffer
 	log := logging.New(&buf, false, logging.LevelInfo)
 
-	exitCode, err := runCommand(log, []string{"echo", "test"})
+	exitCode, err := runCommand(log, []string{"echo", "test"}, syscall.SIGTERM, 30*time.Second, 0)
 	if err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
@@ -290,7 +394,7 @@ func TestRunCommandFailure(t *testing.T) {
 	var buf bytes.Buffer
 	log := logging.New(&buf, false, logging.LevelInfo)
 
-	exitCode, err := runCommand(log, []string{"sh", "-c", "exit 7"})
+	exitCode, err := runCommand(log, []string{"sh", "-c", "exit 7"}, syscall.SIGTERM, 30*time.Second, 0)
 	if err != nil {
 		t.Fatalf("expected no error (exit code returned), got: %v", err)
 	}
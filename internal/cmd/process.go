@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+// defaultShutdownSignal is sent to the child's process group when initium
+// itself receives SIGTERM/SIGINT/SIGHUP, ahead of the --shutdown-timeout
+// SIGKILL escalation.
+const defaultShutdownSignal = "SIGTERM"
+
+// defaultMaxLineBytes bounds how much of a single child output line
+// streamLines will buffer before truncating it, so a runaway child writing
+// an unterminated multi-gigabyte "line" can't exhaust initium's memory.
+const defaultMaxLineBytes = 4 * 1024 * 1024
+
+// streamReadBufferBytes is the chunk size streamLines' underlying
+// bufio.Reader reads in, independent of maxLineBytes: a line is assembled
+// from as many chunks as it takes, so this only bounds memory per read.
+const streamReadBufferBytes = 64 * 1024
+
+// parseShutdownSignal validates a --shutdown-signal flag value.
+func parseShutdownSignal(name string) (syscall.Signal, error) {
+	switch strings.ToUpper(name) {
+	case "", "SIGTERM":
+		return syscall.SIGTERM, nil
+	case "SIGINT":
+		return syscall.SIGINT, nil
+	case "SIGHUP":
+		return syscall.SIGHUP, nil
+	case "SIGQUIT":
+		return syscall.SIGQUIT, nil
+	default:
+		return 0, fmt.Errorf("--shutdown-signal must be one of SIGTERM, SIGINT, SIGHUP, SIGQUIT, got %q", name)
+	}
+}
+
+func runCommand(log *logging.Logger, args []string, shutdownSignal syscall.Signal, shutdownTimeout time.Duration, maxLineBytes int) (int, error) {
+	c := newExecCommand(args[0], args[1:]...)
+	return executeAndStream(log, c, shutdownSignal, shutdownTimeout, maxLineBytes)
+}
+
+func newExecCommand(name string, args ...string) *exec.Cmd {
+	c := exec.Command(name, args...)
+	c.Stdin = nil
+	// Run the child in its own process group so a forwarded shutdown signal
+	// (sent to -pid) reaches any grandchildren it spawns too.
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return c
+}
+
+// executeAndStream starts c, streams its stdout/stderr to log, and forwards
+// SIGTERM/SIGINT/SIGHUP received by initium itself to c's process group as
+// shutdownSignal. If c has not exited within shutdownTimeout of that signal,
+// it escalates to SIGKILL. maxLineBytes caps how much of any single output
+// line streamLines will buffer (see its doc comment); 0 uses
+// defaultMaxLineBytes.
+func executeAndStream(log *logging.Logger, c *exec.Cmd, shutdownSignal syscall.Signal, shutdownTimeout time.Duration, maxLineBytes int) (int, error) {
+	if maxLineBytes <= 0 {
+		maxLineBytes = defaultMaxLineBytes
+	}
+
+	stdoutPipe, err := c.StdoutPipe()
+	if err != nil {
+		return -1, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+
+	stderrPipe, err := c.StderrPipe()
+	if err != nil {
+		return -1, fmt.Errorf("creating stderr pipe: %w", err)
+	}
+
+	if err := c.Start(); err != nil {
+		return -1, fmt.Errorf("starting command %q: %w", c.Path, err)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	done := make(chan struct{})
+	defer func() {
+		signal.Stop(sigCh)
+		close(done)
+	}()
+
+	go forwardShutdownSignal(log, c, sigCh, done, shutdownSignal, shutdownTimeout)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	var streamErrMu sync.Mutex
+	var streamErr error
+	recordStreamErr := func(err error) {
+		if err == nil {
+			return
+		}
+		streamErrMu.Lock()
+		defer streamErrMu.Unlock()
+		if streamErr == nil {
+			streamErr = err
+		}
+	}
+
+	go func() {
+		defer wg.Done()
+		recordStreamErr(streamLines(log, stdoutPipe, "stdout", maxLineBytes))
+	}()
+
+	go func() {
+		defer wg.Done()
+		recordStreamErr(streamLines(log, stderrPipe, "stderr", maxLineBytes))
+	}()
+
+	// Drain both pipes fully before returning so no output is lost if the
+	// child is mid-shutdown when it exits.
+	wg.Wait()
+
+	err = c.Wait()
+	if err == nil {
+		if streamErr != nil {
+			return 0, fmt.Errorf("streaming child output: %w", streamErr)
+		}
+		return 0, nil
+	}
+
+	var exitErr *exec.ExitError
+	if ok := asExitError(err, &exitErr); ok {
+		if streamErr != nil {
+			return exitErr.ExitCode(), fmt.Errorf("streaming child output: %w", streamErr)
+		}
+		return exitErr.ExitCode(), nil
+	}
+
+	return -1, err
+}
+
+// forwardShutdownSignal waits for initium to receive a termination signal
+// and relays it to the child's process group, escalating to SIGKILL if the
+// child outlives shutdownTimeout. It returns once the command has exited
+// (signaled by done being closed) whether or not a signal was ever received.
+func forwardShutdownSignal(log *logging.Logger, c *exec.Cmd, sigCh <-chan os.Signal, done <-chan struct{}, shutdownSignal syscall.Signal, shutdownTimeout time.Duration) {
+	select {
+	case sig := <-sigCh:
+		log.Warn("received signal, forwarding to child process group", "signal", sig.String(), "shutdown_signal", shutdownSignal.String(), "pid", fmt.Sprintf("%d", c.Process.Pid))
+		_ = syscall.Kill(-c.Process.Pid, shutdownSignal)
+
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+			log.Warn("child did not exit within shutdown timeout, sending SIGKILL", "shutdown_timeout", shutdownTimeout.String())
+			_ = syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+		}
+	case <-done:
+	}
+}
+
+func asExitError(err error, target **exec.ExitError) bool {
+	if e, ok := err.(*exec.ExitError); ok {
+		*target = e
+		return true
+	}
+	return false
+}
+
+// streamLines reads r line by line and logs each one, until r is
+// exhausted or a read error (other than io.EOF) occurs, which it returns
+// to the caller rather than swallowing. A line longer than maxLineBytes is
+// truncated to that length and logged as a warning rather than dropped, so
+// a misbehaving child can't silently lose output.
+//
+// If a line looks like a JSON object (starts with '{' and parses), its
+// fields are forwarded directly into the logger's structured output —
+// "msg"/"message" becomes the log message and the rest become key/value
+// fields — instead of being re-wrapped whole as the message. Severity is
+// inferred heuristically: a JSON "level" field if present, otherwise an
+// ERROR/WARN substring in the text, otherwise INFO for stdout and WARN for
+// stderr (since tools that don't label their own severity still tend to
+// reserve stderr for problems).
+func streamLines(log *logging.Logger, r io.Reader, stream string, maxLineBytes int) error {
+	if maxLineBytes <= 0 {
+		maxLineBytes = defaultMaxLineBytes
+	}
+
+	reader := bufio.NewReaderSize(r, streamReadBufferBytes)
+	for {
+		line, truncated, err := readLineCapped(reader, maxLineBytes)
+		if len(line) > 0 {
+			logChildLine(log, stream, line, truncated)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("reading %s: %w", stream, err)
+		}
+	}
+}
+
+// readLineCapped reads a single newline-terminated line from r. At most
+// maxLineBytes of it is returned; any remainder up to the next newline is
+// read and discarded rather than buffered, with truncated=true to flag
+// that data was lost. The final line of r is returned alongside io.EOF if
+// it wasn't newline-terminated.
+func readLineCapped(r *bufio.Reader, maxLineBytes int) (line []byte, truncated bool, err error) {
+	var buf []byte
+	for {
+		chunk, readErr := r.ReadSlice('\n')
+		if readErr == nil {
+			// The terminating '\n' isn't line data, so it doesn't count
+			// against maxLineBytes.
+			chunk = chunk[:len(chunk)-1]
+		}
+
+		if remaining := maxLineBytes - len(buf); remaining > 0 {
+			if len(chunk) > remaining {
+				buf = append(buf, chunk[:remaining]...)
+				truncated = true
+			} else {
+				buf = append(buf, chunk...)
+			}
+		} else if len(chunk) > 0 {
+			truncated = true
+		}
+
+		if readErr == nil {
+			return bytes.TrimSuffix(buf, []byte("\r")), truncated, nil
+		}
+		if readErr == bufio.ErrBufferFull {
+			// r's internal buffer filled without finding '\n': this chunk
+			// is a fragment of a line that's already past maxLineBytes,
+			// not a full line, so keep reading until the real newline.
+			continue
+		}
+		return buf, truncated, readErr
+	}
+}
+
+// logChildLine logs a single line of child output at a heuristically
+// inferred severity, forwarding JSON fields directly when the line is a
+// JSON object.
+func logChildLine(log *logging.Logger, stream string, line []byte, truncated bool) {
+	text := string(line)
+
+	if truncated {
+		log.Warn("child output line exceeded --max-line-bytes and was truncated", "stream", stream, "line", text)
+		return
+	}
+
+	if fields, ok := parseJSONLine(text); ok {
+		logJSONLine(log, stream, fields)
+		return
+	}
+
+	logAtLevel(log, severityFromText(text, stream), text, "stream", stream)
+}
+
+// parseJSONLine reports whether text is a JSON object, returning its
+// top-level fields if so. Anything not starting with '{' is assumed to be
+// plain text without paying for a failed unmarshal attempt.
+func parseJSONLine(text string) (map[string]any, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// logJSONLine forwards a child's own structured log line through log
+// directly: its "msg"/"message" field becomes the log message, its
+// "level" field (if any) picks the severity instead of being logged
+// verbatim (renamed to child_level to avoid colliding with initium's own
+// level field), and every other field is forwarded as a key/value pair.
+func logJSONLine(log *logging.Logger, stream string, fields map[string]any) {
+	msg := ""
+	if m, ok := fields["msg"].(string); ok {
+		msg = m
+		delete(fields, "msg")
+	} else if m, ok := fields["message"].(string); ok {
+		msg = m
+		delete(fields, "message")
+	}
+
+	level := logging.LevelInfo
+	if stream == "stderr" {
+		level = logging.LevelWarn
+	}
+	if lvl, ok := fields["level"].(string); ok {
+		level = severityFromLevelName(lvl)
+		fields["child_level"] = lvl
+		delete(fields, "level")
+	}
+
+	// A child field sharing a name with one of initium's own reserved log
+	// fields would otherwise silently overwrite it (e.g. a child's own
+	// "time" or "stream" field clobbering initium's in JSON mode, since
+	// the log entry is built as a flat map). Rename it out of the way
+	// rather than let that happen quietly.
+	for _, reserved := range []string{"time", "stream"} {
+		if _, collides := fields[reserved]; collides {
+			fields["child_"+reserved] = fields[reserved]
+			delete(fields, reserved)
+		}
+	}
+
+	kvs := make([]string, 0, 2+2*len(fields))
+	kvs = append(kvs, "stream", stream)
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		kvs = append(kvs, k, stringifyField(fields[k]))
+	}
+
+	logAtLevel(log, level, msg, kvs...)
+}
+
+// stringifyField renders a JSON field value for forwarding as a log kv.
+// Scalars are formatted directly; objects and arrays are re-marshaled to
+// JSON so they survive forwarding as valid JSON rather than Go's
+// map/slice syntax (and with deterministic key order).
+func stringifyField(v any) string {
+	switch v.(type) {
+	case map[string]any, []any:
+		if data, err := json.Marshal(v); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// severityFromText heuristically infers a severity from a plain-text
+// (non-JSON) line: an ERROR or WARN substring (case-insensitive, so it
+// also catches key=value style logs like level=error) wins; otherwise
+// stderr defaults to WARN, since tools that don't label their own
+// severity still tend to reserve stderr for problems, and stdout to INFO.
+func severityFromText(text, stream string) logging.Level {
+	upper := strings.ToUpper(text)
+	switch {
+	case strings.Contains(upper, "ERROR"):
+		return logging.LevelError
+	case strings.Contains(upper, "WARN"):
+		return logging.LevelWarn
+	}
+	if stream == "stderr" {
+		return logging.LevelWarn
+	}
+	return logging.LevelInfo
+}
+
+// severityFromLevelName maps a child's own JSON "level" field to initium's
+// Level, falling back to INFO for anything unrecognized.
+func severityFromLevelName(name string) logging.Level {
+	switch strings.ToUpper(name) {
+	case "ERROR", "ERR", "FATAL", "PANIC":
+		return logging.LevelError
+	case "WARN", "WARNING":
+		return logging.LevelWarn
+	case "DEBUG", "TRACE":
+		return logging.LevelDebug
+	default:
+		return logging.LevelInfo
+	}
+}
+
+func logAtLevel(log *logging.Logger, level logging.Level, msg string, kvs ...string) {
+	switch level {
+	case logging.LevelDebug:
+		log.Debug(msg, kvs...)
+	case logging.LevelWarn:
+		log.Warn(msg, kvs...)
+	case logging.LevelError:
+		log.Error(msg, kvs...)
+	default:
+		log.Info(msg, kvs...)
+	}
+}
+
+// ExitCodeFromError extracts the exit code from a command error.
+// Used by callers that need to propagate exit codes (e.g., os.Exit).
+func ExitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	// Check if the error message contains an exit code pattern
+	var exitCode int
+	if n, _ := fmt.Sscanf(err.Error(), "migration exited with code %d", &exitCode); n == 1 {
+		return exitCode
+	}
+
+	// Check for underlying process exit status
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus()
+		}
+	}
+
+	return 1
+}
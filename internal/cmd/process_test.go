@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+func TestParseShutdownSignalDefault(t *testing.T) {
+	sig, err := parseShutdownSignal("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sig != syscall.SIGTERM {
+		t.Fatalf("expected default SIGTERM, got %v", sig)
+	}
+}
+
+func TestParseShutdownSignalKnown(t *testing.T) {
+	for name, want := range map[string]syscall.Signal{
+		"SIGTERM": syscall.SIGTERM,
+		"sigint":  syscall.SIGINT,
+		"SIGHUP":  syscall.SIGHUP,
+		"SIGQUIT": syscall.SIGQUIT,
+	} {
+		got, err := parseShutdownSignal(name)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", name, err)
+		}
+		if got != want {
+			t.Fatalf("%q: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestParseShutdownSignalUnknown(t *testing.T) {
+	if _, err := parseShutdownSignal("SIGKILL"); err == nil {
+		t.Fatal("expected error for unsupported shutdown signal")
+	}
+}
+
+func TestExecuteAndStreamForwardsShutdownSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+
+	c := newExecCommand("sh", "-c", `trap 'exit 0' TERM; while true; do sleep 1; done`)
+
+	done := make(chan struct{})
+	var exitCode int
+	var err error
+	go func() {
+		exitCode, err = executeAndStream(log, c, syscall.SIGTERM, 5*time.Second, 0)
+		close(done)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if sigErr := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); sigErr != nil {
+		t.Fatalf("failed to signal self: %v", sigErr)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("executeAndStream did not return after shutdown signal")
+	}
+
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exitCode != 0 {
+		t.Fatalf("expected clean exit via trap, got code %d", exitCode)
+	}
+	if !strings.Contains(buf.String(), "forwarding to child process group") {
+		t.Fatalf("expected shutdown log message, got: %s", buf.String())
+	}
+}
+
+func TestExecuteAndStreamEscalatesToSIGKILL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+
+	// Ignores TERM so the shutdown timeout has to elapse before SIGKILL.
+	c := newExecCommand("sh", "-c", `trap '' TERM; while true; do sleep 1; done`)
+
+	done := make(chan struct{})
+	var exitCode int
+	var err error
+	go func() {
+		exitCode, err = executeAndStream(log, c, syscall.SIGTERM, 500*time.Millisecond, 0)
+		close(done)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if sigErr := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); sigErr != nil {
+		t.Fatalf("failed to signal self: %v", sigErr)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("executeAndStream did not return after shutdown timeout")
+	}
+
+	if err != nil {
+		t.Fatalf("expected no error (exit code returned), got: %v", err)
+	}
+	if exitCode == 0 {
+		t.Fatalf("expected non-zero exit code from SIGKILL, got %d", exitCode)
+	}
+	if !strings.Contains(buf.String(), "sending SIGKILL") {
+		t.Fatalf("expected SIGKILL escalation log message, got: %s", buf.String())
+	}
+}
+
+func TestStreamLinesPlainText(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stdout", 0) }()
+
+	fmt.Fprint(w, "hello world\n")
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "hello world") {
+		t.Fatalf("expected line to be logged, got: %s", buf.String())
+	}
+}
+
+func TestStreamLinesOversizedLineIsTruncatedNotDropped(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stdout", 16) }()
+
+	fmt.Fprintf(w, "%s\nafter\n", strings.Repeat("x", 100))
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "exceeded --max-line-bytes") {
+		t.Fatalf("expected truncation warning, got: %s", out)
+	}
+	if strings.Count(out, strings.Repeat("x", 16)) == 0 {
+		t.Fatalf("expected truncated prefix to still be logged, got: %s", out)
+	}
+	if !strings.Contains(out, "after") {
+		t.Fatalf("expected the line after the oversized one to still be read, got: %s", out)
+	}
+}
+
+func TestStreamLinesJSONLineForwardsFields(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, true, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stdout", 0) }()
+
+	fmt.Fprint(w, `{"msg":"applying migration","level":"warn","file":"0001_init.sql"}`+"\n")
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"msg":"applying migration"`) {
+		t.Fatalf("expected msg field to carry the log message, got: %s", out)
+	}
+	if !strings.Contains(out, `"level":"WARN"`) {
+		t.Fatalf("expected severity inferred from JSON level field, got: %s", out)
+	}
+	if !strings.Contains(out, `"file":"0001_init.sql"`) {
+		t.Fatalf("expected extra JSON fields to be forwarded, got: %s", out)
+	}
+	if !strings.Contains(out, `"child_level":"warn"`) {
+		t.Fatalf("expected original level value preserved under child_level, got: %s", out)
+	}
+}
+
+func TestStreamLinesJSONLineRenamesCollidingReservedFields(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, true, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stdout", 0) }()
+
+	fmt.Fprint(w, `{"msg":"tick","time":"child-clock","stream":"child-stream"}`+"\n")
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"child_time":"child-clock"`) {
+		t.Fatalf("expected colliding time field renamed to child_time, got: %s", out)
+	}
+	if !strings.Contains(out, `"child_stream":"child-stream"`) {
+		t.Fatalf("expected colliding stream field renamed to child_stream, got: %s", out)
+	}
+	if !strings.Contains(out, `"stream":"stdout"`) {
+		t.Fatalf("expected initium's own stream field to survive the collision, got: %s", out)
+	}
+}
+
+func TestStreamLinesJSONLineReserializesNestedFields(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, true, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stdout", 0) }()
+
+	fmt.Fprint(w, `{"msg":"config loaded","settings":{"retries":3,"timeout":"5s"}}`+"\n")
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"settings":"{\"retries\":3,\"timeout\":\"5s\"}"`) {
+		t.Fatalf("expected nested object re-marshaled as JSON rather than Go map syntax, got: %s", out)
+	}
+}
+
+func TestStreamLinesMixedSeverities(t *testing.T) {
+	r, w := io.Pipe()
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelDebug)
+
+	done := make(chan error, 1)
+	go func() { done <- streamLines(log, r, "stderr", 0) }()
+
+	fmt.Fprint(w, "plain info-ish line\nERROR: something broke\n")
+	w.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 logged lines, got %d: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "[WARN]") {
+		t.Fatalf("expected stderr default severity WARN for unlabeled line, got: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "[ERROR]") {
+		t.Fatalf("expected ERROR substring to raise severity, got: %s", lines[1])
+	}
+}
+
+func TestStreamLinesPropagatesReadError(t *testing.T) {
+	boom := errors.New("boom")
+	r := iotest.ErrReader(boom)
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelDebug)
+
+	err := streamLines(log, r, "stdout", 0)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected read error to propagate, got: %v", err)
+	}
+}
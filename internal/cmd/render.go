@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/kitstream/initium/internal/logging"
 	"github.com/kitstream/initium/internal/render"
@@ -18,6 +19,13 @@ func NewRenderCmd(log *logging.Logger) *cobra.Command {
 		workdir      string
 		mode         string
 		jsonLogs     bool
+		valuesFile   string
+		valuesFiles  []string
+		setValues    []string
+		setFiles     []string
+		templateDir  string
+		strict       bool
+		redactKeys   []string
 	)
 
 	cmd := &cobra.Command{
@@ -29,6 +37,43 @@ substitution. Supports two modes:
   envsubst    — replaces ${VAR} and $VAR patterns (default)
   gotemplate  — full Go text/template with env vars as .VarName
 
+gotemplate mode includes a curated, Sprig-inspired function library (upper,
+lower, default, required, trim, indent, nindent, quote, squote, toYaml,
+fromYaml, toJson, fromJson, b64enc, b64dec, sha256sum, trimPrefix,
+trimSuffix, replace, regexReplaceAll, semverCompare, date, env, hasKey,
+list, dict, get, split, join, randAlphaNum, include, and readFile).
+
+--values-file merges a single YAML or JSON file on top of the environment
+variables exposed to the template directly, for existing templates that
+expect values at the top level (e.g. {{.service.name}}). It is a legacy,
+flat-map-only option: it cannot be combined with --values, --set,
+--set-file, or a template referencing .Env, since those build a nested
+.Values/.Files/.Env data shape instead.
+
+--values may be repeated to merge further YAML or JSON files, in order,
+and --set key=value (dotted paths, e.g. --set service.replicas=3) layers
+individual overrides on top of those. --set-file name=path (repeatable)
+reads a file's contents into .Files.name, for values too large or
+binary-ish to pass with --set, e.g. --set-file tls-ca=/etc/ca.pem. All
+three are exposed to the template as .Values/.Files, with environment
+variables moved to .Env instead of the top level. Existing templates that
+use none of the three flags and don't reference .Env keep seeing env vars
+at the top level, so this is backward compatible. readFile reads an
+arbitrary file's contents at render time, sandboxed to --workdir the same
+way --output is, e.g. {{ readFile "ca-bundles/internal.pem" }}.
+--strict turns a reference to a missing key into a render error instead
+of printing "<no value>", matching Helm's --strict/required behavior, and
+required "msg" .Values.foo always errors with msg when the value is
+missing or empty regardless of --strict.
+
+--template-dir loads every file in a directory as named partials (e.g.
+{{ define "header" }}...{{ end }} in _helpers.tpl), so the main template
+can reference them with {{ template "header" . }} or {{ include "header" . }}.
+
+--redact-key may be repeated to name leaf keys (e.g. password, api-key)
+whose values are replaced with *** before the render context is ever
+logged, so a template execution error can't leak secrets to stdout.
+
 Output files are written relative to --workdir with path traversal prevention.
 Intermediate directories are created automatically.`,
 		Example: `  # envsubst mode (default)
@@ -37,11 +82,27 @@ Intermediate directories are created automatically.`,
   # Go template mode
   initium render --mode gotemplate --template /templates/app.conf.tmpl --output app.conf
 
+  # Go template mode with structured values and strict key checking
+  initium render --mode gotemplate --template /tpl/app.yaml.tmpl --output app.yaml \
+    --values-file /config/values.yaml --strict --redact-key password --redact-key api-key
+
+  # Go template mode with layered values, overrides, and partials
+  initium render --mode gotemplate --template /tpl/app.yaml.tmpl --output app.yaml \
+    --values /config/base.yaml --values /config/prod.yaml --set replicas=3 \
+    --template-dir /tpl/partials
+
+  # Go template mode with a file's contents exposed as .Files.tls-ca
+  initium render --mode gotemplate --template /tpl/app.yaml.tmpl --output app.yaml \
+    --set-file tls-ca=/etc/ssl/internal-ca.pem
+
   # Custom workdir
   initium render --template /tpl/nginx.conf.tmpl --output nginx.conf --workdir /etc/nginx`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rootSpan := tracer.Start(cmd.Context(), "render")
+			defer rootSpan.End()
+
 			if jsonLogs {
 				log.SetJSON(true)
 			}
@@ -61,7 +122,7 @@ Intermediate directories are created automatically.`,
 				return fmt.Errorf("invalid output path: %w", err)
 			}
 
-			data, err := os.ReadFile(templatePath)
+			tplBytes, err := os.ReadFile(templatePath)
 			if err != nil {
 				return fmt.Errorf("reading template %s: %w", templatePath, err)
 			}
@@ -71,10 +132,73 @@ Intermediate directories are created automatically.`,
 			var result string
 			switch mode {
 			case "envsubst":
-				result = render.Envsubst(string(data))
+				result = render.Envsubst(string(tplBytes))
 			case "gotemplate":
-				result, err = render.GoTemplate(string(data))
+				var templateData any
+				var loggableData map[string]any
+
+				if len(valuesFiles) > 0 || len(setValues) > 0 || len(setFiles) > 0 || strings.Contains(string(tplBytes), ".Env") {
+					values := make(map[string]any)
+					for _, f := range valuesFiles {
+						fileValues, err := render.LoadValuesFile(f)
+						if err != nil {
+							return err
+						}
+						render.MergeValues(values, fileValues)
+					}
+					for _, expr := range setValues {
+						if err := render.SetValue(values, expr); err != nil {
+							return err
+						}
+					}
+
+					files := make(map[string]string, len(setFiles))
+					for _, expr := range setFiles {
+						name, path, ok := strings.Cut(expr, "=")
+						if !ok {
+							return fmt.Errorf("--set-file %q must be in name=path form", expr)
+						}
+						content, err := os.ReadFile(path)
+						if err != nil {
+							return fmt.Errorf("reading --set-file %s: %w", path, err)
+						}
+						files[name] = string(content)
+					}
+
+					env := render.EnvMap()
+					templateData = render.TemplateData{Env: env, Files: files, Values: values}
+					loggableData = map[string]any{"Env": env, "Files": files, "Values": values}
+				} else {
+					envData := render.EnvData()
+					templateData = envData
+					loggableData = envData
+				}
+
+				if valuesFile != "" {
+					values, err := render.LoadValuesFile(valuesFile)
+					if err != nil {
+						return err
+					}
+					flat, ok := templateData.(map[string]any)
+					if !ok {
+						return fmt.Errorf("--values-file cannot be combined with --values/--set/--set-file or a template referencing .Env")
+					}
+					for k, v := range values {
+						flat[k] = v
+					}
+					templateData = flat
+					loggableData = flat
+				}
+
+				result, err = render.GoTemplateWithDataDirWorkdir(string(tplBytes), templateData, strict, templateDir, workdir)
 				if err != nil {
+					if len(redactKeys) > 0 {
+						// Info, not Debug: the whole point of --redact-key is
+						// to surface render context on failure, and the
+						// production binary always runs at LevelInfo (no
+						// flag raises it), so Debug here would never emit.
+						log.Info("render context at failure", "data", fmt.Sprintf("%v", render.RedactMap(loggableData, redactKeys)))
+					}
 					return fmt.Errorf("rendering template: %w", err)
 				}
 			}
@@ -97,6 +221,13 @@ Intermediate directories are created automatically.`,
 	cmd.Flags().StringVar(&workdir, "workdir", "/work", "Working directory for output files")
 	cmd.Flags().StringVar(&mode, "mode", "envsubst", "Template mode: envsubst or gotemplate")
 	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	cmd.Flags().StringVar(&valuesFile, "values-file", "", "YAML or JSON file merged on top of env vars for gotemplate mode")
+	cmd.Flags().StringArrayVar(&valuesFiles, "values", nil, "YAML or JSON file exposed as .Values for gotemplate mode (repeatable, merged in order)")
+	cmd.Flags().StringArrayVar(&setValues, "set", nil, "Dotted key=value override applied on top of --values (repeatable)")
+	cmd.Flags().StringArrayVar(&setFiles, "set-file", nil, "name=path pair exposing a file's contents as .Files.name (repeatable)")
+	cmd.Flags().StringVar(&templateDir, "template-dir", "", "Directory of gotemplate partials to load alongside --template")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail if a gotemplate references a key missing from the render data")
+	cmd.Flags().StringArrayVar(&redactKeys, "redact-key", nil, "Leaf key to redact as *** in render context logged on failure (repeatable)")
 
 	return cmd
 }
@@ -243,6 +243,288 @@ func TestRenderCmdJSONOutput(t *testing.T) {
 	}
 }
 
+func TestRenderCmdGoTemplateValuesFile(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("name={{.service.name}}\n"), 0o644)
+
+	valuesFile := filepath.Join(tmplDir, "values.yaml")
+	os.WriteFile(valuesFile, []byte("service:\n  name: myapp\n"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--values-file", valuesFile,
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "name=myapp\n" {
+		t.Fatalf("expected %q, got %q", "name=myapp\n", string(content))
+	}
+}
+
+func TestRenderCmdGoTemplateStrictMissingKeyFails(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("name={{.Missing}}\n"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--strict",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for missing key in strict mode")
+	}
+}
+
+func TestRenderCmdGoTemplateRedactsFailureContext(t *testing.T) {
+	t.Setenv("RENDER_TEST_SECRET", "hunter2")
+
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("{{required \"boom\" .Missing}}"), 0o644)
+
+	workdir := t.TempDir()
+
+	var buf bytes.Buffer
+	log := logging.New(&buf, false, logging.LevelInfo)
+	cmd := NewRenderCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--redact-key", "RENDER_TEST_SECRET",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error from required helper")
+	}
+
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Fatalf("expected secret to be redacted from failure log, got: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "***") {
+		t.Fatalf("expected *** placeholder in failure log, got: %s", buf.String())
+	}
+}
+
+func TestRenderCmdGoTemplateValuesAndSet(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("name={{.Values.service.name}} replicas={{.Values.service.replicas}}\n"), 0o644)
+
+	valuesFile := filepath.Join(tmplDir, "values.yaml")
+	os.WriteFile(valuesFile, []byte("service:\n  name: myapp\n  replicas: 1\n"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--values", valuesFile,
+		"--set", "service.replicas=3",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "name=myapp replicas=3\n" {
+		t.Fatalf("unexpected content: %q", string(content))
+	}
+}
+
+func TestRenderCmdGoTemplateEnvReferenceUsesNestedShape(t *testing.T) {
+	t.Setenv("RENDER_TEST_ENV_NESTED", "envval")
+
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("val={{.Env.RENDER_TEST_ENV_NESTED}}\n"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "val=envval\n" {
+		t.Fatalf("unexpected content: %q", string(content))
+	}
+}
+
+func TestRenderCmdGoTemplateTemplateDir(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte(`{{template "header" .}}`), 0o644)
+
+	partialsDir := t.TempDir()
+	os.WriteFile(filepath.Join(partialsDir, "_helpers.tpl"), []byte(`{{define "header"}}name={{.RENDER_TEST_PARTIAL}}{{end}}`), 0o644)
+
+	t.Setenv("RENDER_TEST_PARTIAL", "app")
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--template-dir", partialsDir,
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "name=app" {
+		t.Fatalf("unexpected content: %q", string(content))
+	}
+}
+
+func TestRenderCmdGoTemplateSetFileExposesFileContents(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte(`ca={{index .Files "tls-ca"}}`+"\n"), 0o644)
+
+	caFile := filepath.Join(tmplDir, "ca.pem")
+	os.WriteFile(caFile, []byte("-----BEGIN CERTIFICATE-----"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--set-file", "tls-ca=" + caFile,
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "ca=-----BEGIN CERTIFICATE-----\n" {
+		t.Fatalf("unexpected content: %q", string(content))
+	}
+}
+
+func TestRenderCmdGoTemplateSetFileInvalidFormat(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte("ca={{.Files.tls-ca}}\n"), 0o644)
+
+	workdir := t.TempDir()
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+		"--set-file", "no-equals-sign",
+	})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error for malformed --set-file value")
+	}
+	if !strings.Contains(err.Error(), "name=path") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRenderCmdGoTemplateReadFileSandboxedToWorkdir(t *testing.T) {
+	tmplDir := t.TempDir()
+	tmplFile := filepath.Join(tmplDir, "app.conf.tmpl")
+	os.WriteFile(tmplFile, []byte(`motd={{readFile "motd.txt"}}`), 0o644)
+
+	workdir := t.TempDir()
+	os.WriteFile(filepath.Join(workdir, "motd.txt"), []byte("welcome"), 0o644)
+
+	log := logging.Default()
+	cmd := NewRenderCmd(log)
+	cmd.SetArgs([]string{
+		"--template", tmplFile,
+		"--output", "app.conf",
+		"--workdir", workdir,
+		"--mode", "gotemplate",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "app.conf"))
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(content) != "motd=welcome" {
+		t.Fatalf("unexpected content: %q", string(content))
+	}
+}
+
 func TestRenderCmdEmptyTemplate(t *testing.T) {
 	tmplDir := t.TempDir()
 	tmplFile := filepath.Join(tmplDir, "empty.tmpl")
@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kitstream/initium/internal/logging"
 	"github.com/spf13/cobra"
 )
 
 func NewSeedCmd(log *logging.Logger) *cobra.Command {
-	var jsonLogs bool
+	var (
+		workdir         string
+		jsonLogs        bool
+		shutdownSignal  string
+		shutdownTimeout time.Duration
+		maxLineBytes    int
+	)
+	var gitFlags *gitSourceFlags
 
 	cmd := &cobra.Command{
 		Use:   "seed -- COMMAND [ARGS...]",
@@ -19,7 +27,29 @@ The command is executed directly via execve (no shell). Use "--" to separate
 initium flags from the seed command and its arguments.
 
 Unlike migrate, seed has no idempotency hints — it is the caller's responsibility
-to ensure seed operations are safe to repeat or are only run once.`,
+to ensure seed operations are safe to repeat or are only run once.
+
+If --from-git is set, that repository is shallow-cloned into a temp
+directory (cleaned up on exit) and used as the effective --workdir instead,
+so seed data can live in version control rather than being baked into the
+image. --git-ref pins a branch, tag, or commit SHA; --git-subdir selects a
+directory within the clone; --git-ssh-key and --git-token authenticate to
+private repos over SSH and HTTPS respectively; --timeout bounds how long
+the clone itself is allowed to take.
+
+The seed command runs in its own process group. If initium itself
+receives SIGTERM, SIGINT, or SIGHUP, --shutdown-signal (default SIGTERM)
+is forwarded to that process group so it gets a chance to exit cleanly;
+if it hasn't exited within --shutdown-timeout (default 30s), it is killed
+with SIGKILL.
+
+A seed command line starting with '{' that parses as JSON has its fields
+forwarded directly into the log output instead of being wrapped whole as
+the message; severity is inferred from a JSON "level" field, an
+ERROR/WARN substring in plain text, or stderr defaulting to WARN.
+--max-line-bytes (default 4MiB) caps how much of a single line is
+buffered before it's truncated with a warning rather than silently
+dropped.`,
 		Example: `  # Seed from a SQL file
   initium seed -- psql -f /seeds/data.sql
 
@@ -27,10 +57,16 @@ to ensure seed operations are safe to repeat or are only run once.`,
   initium seed -- /app/seed --file /seeds/data.sql
 
   # Seed with JSON logs
-  initium seed --json -- python3 /scripts/seed.py`,
+  initium seed --json -- python3 /scripts/seed.py
+
+  # Seed from a script checked out from a Git repo
+  initium seed --from-git https://github.com/acme/seeds.git --git-subdir sql -- psql -f data.sql`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rootSpan := tracer.Start(cmd.Context(), "seed")
+			defer rootSpan.End()
+
 			if jsonLogs {
 				log.SetJSON(true)
 			}
@@ -39,9 +75,20 @@ to ensure seed operations are safe to repeat or are only run once.`,
 				return fmt.Errorf("seed command is required after \"--\"")
 			}
 
+			sig, err := parseShutdownSignal(shutdownSignal)
+			if err != nil {
+				return err
+			}
+
+			effectiveWorkdir, cleanup, err := gitFlags.resolveWorkdir(cmd.Context(), log, workdir)
+			if err != nil {
+				return fmt.Errorf("resolving --from-git source: %w", err)
+			}
+			defer cleanup()
+
 			log.Info("starting seed", "command", args[0])
 
-			exitCode, err := runCommand(log, args)
+			exitCode, err := runCommandInDir(log, args, effectiveWorkdir, sig, shutdownTimeout, maxLineBytes)
 			if err != nil {
 				return fmt.Errorf("seed failed: %w", err)
 			}
@@ -55,7 +102,12 @@ to ensure seed operations are safe to repeat or are only run once.`,
 		},
 	}
 
+	cmd.Flags().StringVar(&workdir, "workdir", "", "Working directory for the seed command (default: inherit)")
 	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+	cmd.Flags().StringVar(&shutdownSignal, "shutdown-signal", defaultShutdownSignal, "Signal to forward to the seed command's process group on SIGTERM/SIGINT/SIGHUP: SIGTERM, SIGINT, SIGHUP, or SIGQUIT")
+	cmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "How long to wait for the seed command to exit after forwarding --shutdown-signal before sending SIGKILL")
+	cmd.Flags().IntVar(&maxLineBytes, "max-line-bytes", defaultMaxLineBytes, "Maximum bytes of a single output line to buffer before truncating it with a warning")
+	gitFlags = registerGitSourceFlags(cmd)
 
 	return cmd
 }
@@ -0,0 +1,10 @@
+package cmd
+
+import "testing"
+
+func TestRecordTargetReadyDoesNotPanic(t *testing.T) {
+	// No telemetry provider is installed in tests, so this exercises the
+	// no-op otel instruments and the target/scheme parsing only.
+	recordTargetReady("tcp://127.0.0.1:1234", 0)
+	recordTargetReady("not a url at all", 0)
+}
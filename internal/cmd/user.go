@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/user"
+	"github.com/spf13/cobra"
+)
+
+func NewUserCmd(log *logging.Logger) *cobra.Command {
+	defaults := user.DefaultConfig()
+
+	var (
+		uid            int
+		gid            int
+		username       string
+		home           string
+		shell          string
+		gecos          string
+		passwdTemplate string
+		groupTemplate  string
+		workdir        string
+		passwdOutput   string
+		groupOutput    string
+		nsswitch       bool
+		nsswitchOutput string
+		stdoutOnly     bool
+		jsonLogs       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Generate /etc/passwd and /etc/group entries for the running UID/GID",
+		Long: `Synthesize /etc/passwd and /etc/group entries for a UID/GID that has no
+matching record, so that tools relying on getpwuid(3) (psql, git, ssh, ...)
+don't refuse to run. This is the common "arbitrary UID" problem on OpenShift
+and PSP/PSA-restricted Kubernetes, where containers run as a UID chosen by
+the platform rather than the one baked into the image.
+
+By default the entries are written atomically to --workdir/--passwd-output
+and --workdir/--group-output. If --passwd-template/--group-template point at
+existing files (e.g. the image's own /etc/passwd), their content is copied
+and the new entry appended rather than replacing them; an entry for the
+given UID/GID that already exists there is left untouched, so repeated runs
+never duplicate entries. --stdout-only skips all file writes and only prints
+the two generated lines, for initContainers that hand them off via a shared
+emptyDir themselves.`,
+		Example: `  # Make the current arbitrary UID resolvable in place
+  initium user --workdir /etc --passwd-template /etc/passwd --group-template /etc/group
+
+  # Explicit identity, with a matching nsswitch.conf
+  initium user --uid 1001 --gid 0 --username app --home /home/app --shell /bin/sh --nsswitch
+
+  # Print the lines only, for a sidecar to write into a shared emptyDir
+  initium user --uid 1001 --gid 0 --username app --stdout-only`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, rootSpan := tracer.Start(cmd.Context(), "user")
+			defer rootSpan.End()
+
+			if jsonLogs {
+				log.SetJSON(true)
+			}
+
+			cfg := user.Config{
+				UID:            uid,
+				GID:            gid,
+				Username:       username,
+				Home:           home,
+				Shell:          shell,
+				Gecos:          gecos,
+				PasswdTemplate: passwdTemplate,
+				GroupTemplate:  groupTemplate,
+				Workdir:        workdir,
+				PasswdOutput:   passwdOutput,
+				GroupOutput:    groupOutput,
+				Nsswitch:       nsswitch,
+				NsswitchOutput: nsswitchOutput,
+				StdoutOnly:     stdoutOnly,
+			}
+
+			result, err := user.Do(cfg)
+			if err != nil {
+				return fmt.Errorf("generating user entries: %w", err)
+			}
+
+			if stdoutOnly {
+				fmt.Fprintln(cmd.OutOrStdout(), result.PasswdLine)
+				fmt.Fprintln(cmd.OutOrStdout(), result.GroupLine)
+				return nil
+			}
+
+			log.Info("user entries written", "passwd", result.PasswdPath, "group", result.GroupPath, "username", username, "uid", fmt.Sprintf("%d", uid))
+			if result.NsswitchPath != "" {
+				log.Info("nsswitch.conf written", "path", result.NsswitchPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&uid, "uid", defaults.UID, "UID to generate an entry for (default: current process UID)")
+	cmd.Flags().IntVar(&gid, "gid", defaults.GID, "GID to generate an entry for (default: current process GID)")
+	cmd.Flags().StringVar(&username, "username", defaults.Username, "Username for the generated entries")
+	cmd.Flags().StringVar(&home, "home", defaults.Home, "Home directory for the generated passwd entry")
+	cmd.Flags().StringVar(&shell, "shell", defaults.Shell, "Login shell for the generated passwd entry")
+	cmd.Flags().StringVar(&gecos, "gecos", "", "GECOS field for the generated passwd entry")
+	cmd.Flags().StringVar(&passwdTemplate, "passwd-template", "", "Existing passwd file to copy and append to, instead of creating a minimal one")
+	cmd.Flags().StringVar(&groupTemplate, "group-template", "", "Existing group file to copy and append to, instead of creating a minimal one")
+	cmd.Flags().StringVar(&workdir, "workdir", "/etc", "Working directory for output files")
+	cmd.Flags().StringVar(&passwdOutput, "passwd-output", defaults.PasswdOutput, "Output file path relative to workdir for the passwd entry")
+	cmd.Flags().StringVar(&groupOutput, "group-output", defaults.GroupOutput, "Output file path relative to workdir for the group entry")
+	cmd.Flags().BoolVar(&nsswitch, "nsswitch", false, "Also write a minimal nsswitch.conf that prefers files")
+	cmd.Flags().StringVar(&nsswitchOutput, "nsswitch-output", defaults.NsswitchOutput, "Output file path relative to workdir for nsswitch.conf")
+	cmd.Flags().BoolVar(&stdoutOnly, "stdout-only", false, "Print the generated lines to stdout instead of writing any files")
+	cmd.Flags().BoolVar(&jsonLogs, "json", false, "Enable JSON log output")
+
+	return cmd
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+func TestUserCmdWritesPasswdAndGroup(t *testing.T) {
+	workdir := t.TempDir()
+
+	var buf bytes.Buffer
+	lg := logging.New(&buf, false, logging.LevelInfo)
+	c := NewUserCmd(lg)
+	c.SetArgs([]string{
+		"--uid", "1001",
+		"--gid", "1001",
+		"--username", "app",
+		"--workdir", workdir,
+	})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("user command failed: %v", err)
+	}
+
+	passwd, err := os.ReadFile(filepath.Join(workdir, "passwd"))
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+	if !strings.Contains(string(passwd), "app:x:1001:1001:") {
+		t.Fatalf("unexpected passwd content: %s", passwd)
+	}
+}
+
+func TestUserCmdStdoutOnly(t *testing.T) {
+	workdir := t.TempDir()
+
+	var out bytes.Buffer
+	lg := logging.Default()
+	c := NewUserCmd(lg)
+	c.SetOut(&out)
+	c.SetArgs([]string{
+		"--uid", "1001",
+		"--gid", "1001",
+		"--username", "app",
+		"--workdir", workdir,
+		"--stdout-only",
+	})
+
+	if err := c.Execute(); err != nil {
+		t.Fatalf("user command failed: %v", err)
+	}
+
+	if !strings.Contains(out.String(), "app:x:1001:1001:") {
+		t.Fatalf("expected passwd line on stdout, got: %s", out.String())
+	}
+
+	entries, err := os.ReadDir(workdir)
+	if err != nil {
+		t.Fatalf("reading workdir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no files written in stdout-only mode, found %d", len(entries))
+	}
+}
+
+func TestUserCmdInvalidUID(t *testing.T) {
+	lg := logging.Default()
+	c := NewUserCmd(lg)
+	c.SilenceUsage = true
+	c.SilenceErrors = true
+	c.SetArgs([]string{"--uid", "-1"})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("expected error for negative uid")
+	}
+}
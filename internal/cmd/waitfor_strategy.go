@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kitstream/initium/internal/logging"
+	"github.com/kitstream/initium/internal/retry"
+)
+
+// quorumNeeded translates --strategy/--quorum into the number of targets
+// that must succeed for waitForTargets to consider the command successful.
+func quorumNeeded(strategy string, quorum, numTargets int) (int, error) {
+	switch strategy {
+	case "all":
+		return numTargets, nil
+	case "any":
+		return 1, nil
+	case "quorum":
+		if quorum < 1 || quorum > numTargets {
+			return 0, fmt.Errorf("--quorum must be between 1 and the number of targets (%d), got %d", numTargets, quorum)
+		}
+		return quorum, nil
+	default:
+		return 0, fmt.Errorf("--strategy must be all, any, or quorum, got %q", strategy)
+	}
+}
+
+type targetOutcome struct {
+	target   string
+	err      error
+	attempts int
+	elapsed  time.Duration
+}
+
+// waitForTargets checks every target concurrently, each with its own retry
+// state (own attempt counter and backoff), bounded by parallelism (0 means
+// unbounded). It returns as soon as needed targets have succeeded or it
+// becomes impossible to reach that many, cancelling any still-running checks.
+func waitForTargets(ctx context.Context, log *logging.Logger, targets []string, cfg retry.Config, opts checkerOptions, strategy string, needed, parallelism int) error {
+	runCtx, runCancel := context.WithCancel(ctx)
+	defer runCancel()
+
+	limit := parallelism
+	if limit <= 0 || limit > len(targets) {
+		limit = len(targets)
+	}
+	sem := make(chan struct{}, limit)
+
+	results := make(chan targetOutcome, len(targets))
+	var wg sync.WaitGroup
+
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			log.Info("waiting for target", "target", target)
+			checker, err := newCheckerFromOptions(target, opts)
+			if err != nil {
+				results <- targetOutcome{target: target, err: err}
+				return
+			}
+
+			start := time.Now()
+			result := retry.DoWithHooks(runCtx, cfg, func(ctx context.Context, attempt int) error {
+				attemptErr := checker(ctx)
+				outcome := "success"
+				if attemptErr != nil {
+					outcome = "failure"
+				}
+				log.Debug("target attempt", "target", target, "attempt", fmt.Sprintf("%d", attempt+1), "outcome", outcome, "latency", time.Since(start).String())
+				return attemptErr
+			}, func(attempt int, err error, nextDelay time.Duration) {
+				log.Debug("retrying target", "target", target, "attempt", fmt.Sprintf("%d", attempt+1), "error", err.Error(), "next_delay", nextDelay.String())
+			})
+
+			results <- targetOutcome{target: target, err: result.Err, attempts: result.Attempt + 1, elapsed: time.Since(start)}
+		}(target)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	successes := 0
+	failures := 0
+	var lastErr error
+
+	for i := 0; i < len(targets); i++ {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				successes++
+				log.Info("target is reachable", "target", r.target, "attempts", fmt.Sprintf("%d", r.attempts), "latency", r.elapsed.String())
+				recordTargetReady(r.target, r.elapsed)
+			} else {
+				failures++
+				lastErr = r.err
+				log.Warn("target not reachable", "target", r.target, "error", r.err.Error())
+			}
+
+			if successes >= needed {
+				runCancel()
+				log.Info("strategy satisfied", "strategy", strategy, "reachable", fmt.Sprintf("%d", successes), "needed", fmt.Sprintf("%d", needed))
+				return nil
+			}
+			remaining := len(targets) - successes - failures
+			if successes+remaining < needed {
+				runCancel()
+				return fmt.Errorf("strategy %s cannot be satisfied: only %d/%d targets reachable (needed %d), last error: %w", strategy, successes, len(targets), needed, lastErr)
+			}
+		case <-ctx.Done():
+			runCancel()
+			return fmt.Errorf("timed out waiting for targets: %w", ctx.Err())
+		}
+	}
+
+	return fmt.Errorf("strategy %s not satisfied: only %d/%d targets reachable (needed %d), last error: %w", strategy, successes, len(targets), needed, lastErr)
+}
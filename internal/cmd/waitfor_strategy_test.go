@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kitstream/initium/internal/logging"
+)
+
+func TestQuorumNeededAll(t *testing.T) {
+	needed, err := quorumNeeded("all", 0, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if needed != 4 {
+		t.Fatalf("expected 4, got %d", needed)
+	}
+}
+
+func TestQuorumNeededAny(t *testing.T) {
+	needed, err := quorumNeeded("any", 0, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if needed != 1 {
+		t.Fatalf("expected 1, got %d", needed)
+	}
+}
+
+func TestQuorumNeededQuorum(t *testing.T) {
+	needed, err := quorumNeeded("quorum", 2, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if needed != 2 {
+		t.Fatalf("expected 2, got %d", needed)
+	}
+}
+
+func TestQuorumNeededQuorumOutOfBounds(t *testing.T) {
+	if _, err := quorumNeeded("quorum", 0, 4); err == nil {
+		t.Fatal("expected error for quorum below 1")
+	}
+	if _, err := quorumNeeded("quorum", 5, 4); err == nil {
+		t.Fatal("expected error for quorum above numTargets")
+	}
+}
+
+func TestQuorumNeededInvalidStrategy(t *testing.T) {
+	if _, err := quorumNeeded("majority", 0, 4); err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+}
+
+func TestWaitForCmdStrategyAnySucceedsWithOneReachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--target", "tcp://127.0.0.1:1",
+		"--strategy", "any",
+		"--max-attempts", "2",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected strategy any to succeed, got: %v", err)
+	}
+}
+
+func TestWaitForCmdStrategyAllFailsWithOneUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--target", "tcp://127.0.0.1:1",
+		"--max-attempts", "2",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "2s",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected strategy all to fail when a target is unreachable")
+	}
+}
+
+func TestWaitForCmdStrategyQuorumSatisfiedBeforeLastTarget(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--target", fmt.Sprintf("tcp://%s", listener.Addr().String()),
+		"--target", "tcp://127.0.0.1:1",
+		"--strategy", "quorum",
+		"--quorum", "2",
+		"--max-attempts", "2",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected quorum of 2/3 to succeed, got: %v", err)
+	}
+}
+
+func TestWaitForCmdInvalidStrategyFlag(t *testing.T) {
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--target", "tcp://127.0.0.1:1",
+		"--strategy", "bogus",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error for invalid --strategy value")
+	}
+}
+
+func TestWaitForCmdSucceedsWithBoundedParallelism(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create listener: %v", err)
+	}
+	defer listener.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SetArgs([]string{
+		"--target", fmt.Sprintf("tcp://%s", listener.Addr().String()),
+		"--target", fmt.Sprintf("tcp://%s", listener.Addr().String()),
+		"--parallelism", "1",
+		"--max-attempts", "3",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success with bounded parallelism, got: %v", err)
+	}
+}
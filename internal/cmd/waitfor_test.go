@@ -2,10 +2,15 @@ package cmd
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -97,6 +102,79 @@ func TestNewCheckerHTTPS(t *testing.T) {
 	}
 }
 
+func TestNewHTTPCheckerCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caPath, pemBytes, 0o644); err != nil {
+		t.Fatalf("writing ca file: %v", err)
+	}
+
+	checker, err := newHTTPChecker(srv.URL, checkerOptions{expectedStatus: 200, timeout: 5 * time.Second, caFile: caPath})
+	if err != nil {
+		t.Fatalf("newHTTPChecker failed: %v", err)
+	}
+	if err := checker(context.Background()); err != nil {
+		t.Fatalf("expected success trusting --ca-file, got: %v", err)
+	}
+}
+
+func TestNewHTTPCheckerCAFileInvalidPEM(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte("not a cert"), 0o644); err != nil {
+		t.Fatalf("writing ca file: %v", err)
+	}
+
+	if _, err := newHTTPChecker("https://example.com", checkerOptions{caFile: caPath}); err == nil {
+		t.Fatal("expected error for invalid --ca-file contents")
+	}
+}
+
+func TestNewHTTPCheckerClientCertRequiresKey(t *testing.T) {
+	if _, err := newHTTPChecker("https://example.com", checkerOptions{clientCert: "/tmp/does-not-matter.pem"}); err == nil {
+		t.Fatal("expected error when --client-cert is set without --client-key")
+	}
+}
+
+func TestNewHTTPCheckerProxyURL(t *testing.T) {
+	proxyURL, err := url.Parse("http://127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("parsing proxy url: %v", err)
+	}
+
+	checker, err := newHTTPChecker("http://example.com", checkerOptions{expectedStatus: 200, timeout: time.Second, proxyURL: proxyURL})
+	if err != nil {
+		t.Fatalf("newHTTPChecker failed: %v", err)
+	}
+	if checker == nil {
+		t.Fatal("expected a non-nil checker")
+	}
+}
+
+func TestCheckKeyFilePermissionsRejectsGroupReadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, []byte("key"), 0o640); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+	if err := checkKeyFilePermissions(path); err == nil {
+		t.Fatal("expected error for group-readable key file")
+	}
+}
+
+func TestCheckKeyFilePermissionsAcceptsOwnerOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, []byte("key"), 0o600); err != nil {
+		t.Fatalf("writing key file: %v", err)
+	}
+	if err := checkKeyFilePermissions(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestNewCheckerInvalidScheme(t *testing.T) {
 	_, err := newChecker("ftp://example.com", 200, false, 5*time.Second)
 	if err == nil {
@@ -104,6 +182,86 @@ func TestNewCheckerInvalidScheme(t *testing.T) {
 	}
 }
 
+func TestNewCheckerDNSResolvesLocalhost(t *testing.T) {
+	checker, err := newChecker("dns://localhost", 200, false, 5*time.Second)
+	if err != nil {
+		t.Fatalf("newChecker failed: %v", err)
+	}
+
+	if err := checker(context.Background()); err != nil {
+		t.Fatalf("DNS check failed: %v", err)
+	}
+}
+
+func TestNewCheckerDNSExpectTooMany(t *testing.T) {
+	checker, err := newChecker("dns://localhost?expect=1000", 200, false, 5*time.Second)
+	if err != nil {
+		t.Fatalf("newChecker failed: %v", err)
+	}
+
+	if err := checker(context.Background()); err == nil {
+		t.Fatal("expected error when fewer records than requested are returned")
+	}
+}
+
+func TestNewCheckerS3MissingBucket(t *testing.T) {
+	_, err := newChecker("s3://", 200, false, 5*time.Second)
+	if err == nil {
+		t.Fatal("expected error for s3 target without a bucket")
+	}
+}
+
+func TestNewCheckerRedissRegistered(t *testing.T) {
+	if _, err := newChecker("rediss://localhost:6379", 200, false, 5*time.Second); err != nil {
+		t.Fatalf("expected rediss:// to be a recognized scheme, got: %v", err)
+	}
+}
+
+func TestMySQLDSNAppliesInsecureTLS(t *testing.T) {
+	u, err := url.Parse("mysql://user:pass@db:3306/app")
+	if err != nil {
+		t.Fatalf("parsing target: %v", err)
+	}
+
+	dsn, err := mysqlDSN(u, checkerOptions{insecureTLS: true})
+	if err != nil {
+		t.Fatalf("mysqlDSN failed: %v", err)
+	}
+	if !strings.Contains(dsn, "tls=skip-verify") {
+		t.Fatalf("expected tls=skip-verify in DSN, got %q", dsn)
+	}
+}
+
+func TestMySQLDSNPreservesExplicitTLSMode(t *testing.T) {
+	u, err := url.Parse("mysql://user:pass@db:3306/app?tls=true")
+	if err != nil {
+		t.Fatalf("parsing target: %v", err)
+	}
+
+	dsn, err := mysqlDSN(u, checkerOptions{insecureTLS: true})
+	if err != nil {
+		t.Fatalf("mysqlDSN failed: %v", err)
+	}
+	if !strings.Contains(dsn, "tls=true") || strings.Contains(dsn, "skip-verify") {
+		t.Fatalf("expected caller's tls=true to be preserved, got %q", dsn)
+	}
+}
+
+func TestRedisCheckerBuildsWithInsecureTLS(t *testing.T) {
+	u, err := url.Parse("rediss://localhost:6379")
+	if err != nil {
+		t.Fatalf("parsing target: %v", err)
+	}
+
+	checker, err := newRedisChecker(u, checkerOptions{insecureTLS: true})
+	if err != nil {
+		t.Fatalf("newRedisChecker failed: %v", err)
+	}
+	if checker == nil {
+		t.Fatal("expected a non-nil checker")
+	}
+}
+
 func TestWaitForCmdNoTargets(t *testing.T) {
 	log := logging.Default()
 	cmd := NewWaitForCmd(log)
@@ -176,6 +334,78 @@ func TestWaitForCmdTCPFailure(t *testing.T) {
 	}
 }
 
+func TestWaitForCmdJSONPathMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ready":false}`))
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--max-attempts", "2",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "2s",
+		"--expect-json-path", "ready",
+		"--expect-json-value", "true",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error when JSON path value does not match")
+	}
+}
+
+func TestWaitForCmdJSONPathMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ready":true}`))
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--max-attempts", "3",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+		"--expect-json-path", "ready",
+		"--expect-json-value", "true",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+}
+
+func TestWaitForCmdExpectStatusRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SetArgs([]string{
+		"--target", srv.URL,
+		"--max-attempts", "3",
+		"--initial-delay", "10ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+		"--expect-status-range", "200-299",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+}
+
 func TestWaitForCmdInvalidRetryConfig(t *testing.T) {
 	log := logging.Default()
 	cmd := NewWaitForCmd(log)
@@ -191,3 +421,23 @@ func TestWaitForCmdInvalidRetryConfig(t *testing.T) {
 		t.Fatal("expected error for invalid retry config")
 	}
 }
+
+func TestWaitForCmdTotalBudgetExhausted(t *testing.T) {
+	log := logging.Default()
+	cmd := NewWaitForCmd(log)
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{
+		"--target", "tcp://127.0.0.1:1",
+		"--max-attempts", "10",
+		"--initial-delay", "50ms",
+		"--max-delay", "50ms",
+		"--timeout", "5s",
+		"--total-budget", "10ms",
+	})
+
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when total budget is exhausted")
+	}
+}
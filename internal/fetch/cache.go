@@ -0,0 +1,265 @@
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/kitstream/initium/internal/safety"
+)
+
+// cacheSidecar is the small JSON file stored next to each cached body,
+// recording what's needed to revalidate or evict it later.
+type cacheSidecar struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	Status       int       `json:"status"`
+	ContentType  string    `json:"content_type,omitempty"`
+}
+
+// cacheResult reports how a cached fetch was actually served, so the cmd
+// layer can log it without internal/fetch depending on internal/logging.
+type cacheResult struct {
+	fromCache bool
+	offline   bool
+}
+
+// cacheKey derives a canonical, stable identifier for a request: method,
+// URL, a sha256 fingerprint of the resolved auth header value (never the
+// value itself, so the cache directory never holds live credentials), and
+// the Accept header. Identical requests always land on the same entry;
+// anything that would change the response lands on a different one.
+func cacheKey(method, rawURL, authFingerprint, accept string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%s", method, rawURL, authFingerprint, accept)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// authFingerprint returns a sha256 fingerprint of the auth header value cfg
+// would send, or "" if cfg sends none.
+func authFingerprint(cfg Config) string {
+	if cfg.AuthEnv == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(os.Getenv(cfg.AuthEnv)))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachePaths returns the body, sidecar, and lock file paths for key, sharded
+// by its first two hex characters so the cache directory never has a single
+// flat directory with huge numbers of entries.
+func cachePaths(dir, key string) (body, sidecar, lock string) {
+	shardDir := filepath.Join(dir, key[:2])
+	return filepath.Join(shardDir, key), filepath.Join(shardDir, key+".json"), filepath.Join(shardDir, key+".lock")
+}
+
+// lockCacheKey takes an exclusive flock on lockPath so that two initium
+// processes racing to populate the same cache entry (e.g. sidecar
+// containers starting simultaneously) serialize instead of both hitting the
+// origin. The returned func releases the lock and must always be called.
+func lockCacheKey(lockPath string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache shard directory: %w", err)
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file %s: %w", lockPath, err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", lockPath, err)
+	}
+
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// readCacheEntry loads a cached body and its sidecar, if both exist.
+func readCacheEntry(bodyPath, sidecarPath string) (cacheSidecar, []byte, bool) {
+	sidecarBytes, err := os.ReadFile(sidecarPath)
+	if err != nil {
+		return cacheSidecar{}, nil, false
+	}
+	var sidecar cacheSidecar
+	if err := json.Unmarshal(sidecarBytes, &sidecar); err != nil {
+		return cacheSidecar{}, nil, false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return cacheSidecar{}, nil, false
+	}
+	return sidecar, body, true
+}
+
+// writeCacheEntry atomically writes body and its sidecar into dir.
+func writeCacheEntry(bodyPath, sidecarPath string, body []byte, sidecar cacheSidecar) error {
+	sidecarBytes, err := json.Marshal(sidecar)
+	if err != nil {
+		return fmt.Errorf("encoding cache sidecar: %w", err)
+	}
+	if err := safety.WriteFileAtomic(bodyPath, body, 0o644); err != nil {
+		return fmt.Errorf("writing cache body: %w", err)
+	}
+	if err := safety.WriteFileAtomic(sidecarPath, sidecarBytes, 0o644); err != nil {
+		return fmt.Errorf("writing cache sidecar: %w", err)
+	}
+	return nil
+}
+
+// touchCacheEntry updates the sidecar's mtime (its atime proxy; some tmpfs
+// and container filesystems mount noatime, so mtime is the portable choice)
+// so the LRU eviction in evictStaleCache treats it as recently used.
+func touchCacheEntry(sidecarPath string) {
+	now := time.Now()
+	_ = os.Chtimes(sidecarPath, now, now)
+}
+
+// evictLRU removes cache entries, oldest-touched first, until the cache
+// directory's total size is at or under maxBytes. It only inspects bodies
+// (not sidecars or lock files) when computing size and age, keyed by each
+// sidecar's mtime as set by touchCacheEntry/writeCacheEntry.
+func evictLRU(dir string, maxBytes int64) error {
+	type entry struct {
+		bodyPath    string
+		sidecarPath string
+		lockPath    string
+		size        int64
+		lastUsed    time.Time
+	}
+
+	var entries []entry
+	var total int64
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) == ".json" || filepath.Ext(path) == ".lock" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		sidecarPath := path + ".json"
+		sidecarInfo, err := os.Stat(sidecarPath)
+		lastUsed := info.ModTime()
+		if err == nil {
+			lastUsed = sidecarInfo.ModTime()
+		}
+		entries = append(entries, entry{
+			bodyPath:    path,
+			sidecarPath: sidecarPath,
+			lockPath:    path + ".lock",
+			size:        info.Size(),
+			lastUsed:    lastUsed,
+		})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walking cache dir %s: %w", dir, err)
+	}
+
+	if total <= maxBytes {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].lastUsed.Before(entries[j].lastUsed) })
+
+	for _, e := range entries {
+		if total <= maxBytes {
+			break
+		}
+		os.Remove(e.bodyPath)
+		os.Remove(e.sidecarPath)
+		os.Remove(e.lockPath)
+		total -= e.size
+	}
+
+	return nil
+}
+
+// fetchHTTPCached wraps doHTTPRequest with a content-addressable on-disk
+// cache: it issues a conditional request using any stored ETag/Last-Modified
+// once the entry is within cfg.CacheMaxAge, accepts a 304 by returning the
+// cached body, and falls back to the cached body (with cacheResult.offline
+// set) when the request fails outright and cfg.Offline is set.
+func fetchHTTPCached(ctx context.Context, cfg Config) ([]byte, cacheResult, error) {
+	key := cacheKey(http.MethodGet, cfg.URL, authFingerprint(cfg), "")
+	bodyPath, sidecarPath, lockPath := cachePaths(cfg.CacheDir, key)
+
+	unlock, err := lockCacheKey(lockPath)
+	if err != nil {
+		return nil, cacheResult{}, err
+	}
+	defer unlock()
+
+	sidecar, cachedBody, hasCache := readCacheEntry(bodyPath, sidecarPath)
+	stale := cfg.CacheMaxAge > 0 && hasCache && time.Since(sidecar.FetchedAt) > cfg.CacheMaxAge
+
+	var condHeaders map[string]string
+	if hasCache && !stale {
+		condHeaders = make(map[string]string, 2)
+		if sidecar.ETag != "" {
+			condHeaders["If-None-Match"] = sidecar.ETag
+		}
+		if sidecar.LastModified != "" {
+			condHeaders["If-Modified-Since"] = sidecar.LastModified
+		}
+	}
+
+	resp, body, err := doHTTPRequest(ctx, cfg, condHeaders)
+	if err != nil {
+		if cfg.Offline && hasCache {
+			touchCacheEntry(sidecarPath)
+			return cachedBody, cacheResult{fromCache: true, offline: true}, nil
+		}
+		return nil, cacheResult{}, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && hasCache {
+		touchCacheEntry(sidecarPath)
+		return cachedBody, cacheResult{fromCache: true}, nil
+	}
+
+	if err := checkStatusRange(cfg, cfg.URL, resp.StatusCode); err != nil {
+		return nil, cacheResult{}, err
+	}
+
+	newSidecar := cacheSidecar{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       sha256Hex(body),
+		FetchedAt:    time.Now(),
+		Status:       resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+	}
+	if err := writeCacheEntry(bodyPath, sidecarPath, body, newSidecar); err != nil {
+		return nil, cacheResult{}, err
+	}
+	if cfg.CacheMaxBytes > 0 {
+		if err := evictLRU(cfg.CacheDir, cfg.CacheMaxBytes); err != nil {
+			return nil, cacheResult{}, err
+		}
+	}
+
+	return body, cacheResult{}, nil
+}
+
+func sha256Hex(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
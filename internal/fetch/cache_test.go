@@ -0,0 +1,219 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDoCacheMissWritesSidecarAndBody(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cacheDir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		CacheDir:   cacheDir,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+	if result.FromCache {
+		t.Fatal("expected first fetch not to be served from cache")
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+
+	key := cacheKey(http.MethodGet, cfg.URL, authFingerprint(cfg), "")
+	bodyPath, sidecarPath, _ := cachePaths(cacheDir, key)
+	if _, err := os.Stat(bodyPath); err != nil {
+		t.Fatalf("expected cache body written: %v", err)
+	}
+	if _, err := os.Stat(sidecarPath); err != nil {
+		t.Fatalf("expected cache sidecar written: %v", err)
+	}
+}
+
+func TestDoCacheHitRevalidatesWith304(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cacheDir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		CacheDir:   cacheDir,
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+	if !result.FromCache {
+		t.Fatal("expected second fetch to be served from cache via 304")
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests (one revalidation), got %d", requests)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.txt"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("expected cached body preserved, got %q", content)
+	}
+}
+
+func TestDoCacheStaleForcesFullRefetch(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") != "" {
+			t.Fatal("expected no conditional headers once the entry is stale")
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cacheDir := t.TempDir()
+	cfg := Config{
+		URL:         srv.URL,
+		OutputPath:  "out.txt",
+		Workdir:     workdir,
+		Timeout:     5 * time.Second,
+		CacheDir:    cacheDir,
+		CacheMaxAge: time.Nanosecond,
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+	if result.FromCache {
+		t.Fatal("expected stale entry to force a full refetch, not a cache hit")
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 full requests, got %d", requests)
+	}
+}
+
+func TestDoOfflineServesCacheOnNetworkFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("hello"))
+	}))
+
+	workdir := t.TempDir()
+	cacheDir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		CacheDir:   cacheDir,
+		Offline:    true,
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+	srv.Close()
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected offline fallback to succeed, got: %v", err)
+	}
+	if !result.FromCache || !result.CacheOffline {
+		t.Fatalf("expected CacheOffline result, got %+v", result)
+	}
+}
+
+func TestDoCacheMaxBytesEvictsOldestEntry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("0123456789"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cacheDir := t.TempDir()
+	cfg1 := Config{
+		URL:           srv.URL + "/a",
+		OutputPath:    "out.txt",
+		Workdir:       workdir,
+		Timeout:       5 * time.Second,
+		CacheDir:      cacheDir,
+		CacheMaxBytes: 10,
+	}
+	cfg2 := cfg1
+	cfg2.URL = srv.URL + "/b"
+
+	if _, err := Do(context.Background(), cfg1); err != nil {
+		t.Fatalf("first fetch failed: %v", err)
+	}
+	key1 := cacheKey(http.MethodGet, cfg1.URL, authFingerprint(cfg1), "")
+	body1, _, _ := cachePaths(cacheDir, key1)
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := Do(context.Background(), cfg2); err != nil {
+		t.Fatalf("second fetch failed: %v", err)
+	}
+
+	if _, err := os.Stat(body1); !os.IsNotExist(err) {
+		t.Fatalf("expected oldest cache entry to be evicted once over --cache-max-bytes, err=%v", err)
+	}
+}
+
+func TestCacheKeyStableAcrossCalls(t *testing.T) {
+	k1 := cacheKey(http.MethodGet, "http://example.com", "", "")
+	k2 := cacheKey(http.MethodGet, "http://example.com", "", "")
+	if k1 != k2 {
+		t.Fatalf("expected stable cache key, got %q and %q", k1, k2)
+	}
+	if k3 := cacheKey(http.MethodGet, "http://example.com/other", "", ""); k3 == k1 {
+		t.Fatal("expected different URLs to produce different cache keys")
+	}
+}
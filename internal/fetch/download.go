@@ -0,0 +1,288 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/kitstream/initium/internal/retry"
+)
+
+// downloadMeta is a small sidecar persisted next to a partial download (see
+// partialPath) so that a later attempt -- whether downloadToFile's own
+// retry loop or a completely separate process after e.g. a container
+// restart -- can detect it and resume with a Range request instead of
+// starting over.
+type downloadMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	AcceptRanges bool   `json:"accept_ranges"`
+}
+
+// partialPath and metaPath name the temp file a download streams into and
+// its resume sidecar. Both live alongside outPath so the eventual
+// os.Rename into place stays on the same filesystem.
+func partialPath(outPath string) string { return outPath + ".part" }
+func metaPath(outPath string) string    { return outPath + ".part.json" }
+
+// downloadAttemptError carries enough information from a single download
+// attempt for downloadToFile to decide whether to retry, and how long to
+// wait if so.
+type downloadAttemptError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+	// statusCode is the HTTP status that produced this error, or 0 if the
+	// attempt never got a response (e.g. a network error).
+	statusCode int
+}
+
+func (e *downloadAttemptError) Error() string { return e.err.Error() }
+func (e *downloadAttemptError) Unwrap() error { return e.err }
+
+// downloadRetryConfig builds a retry.Config used purely for its exponential
+// backoff math (retry.Delay); the attempt loop itself lives in
+// downloadToFile rather than retry.Do, since it needs to tell a retryable
+// failure (network error, 5xx, 429) apart from a terminal one (e.g. a 404,
+// or a checksum mismatch), and retry.Do retries every error unconditionally.
+func downloadRetryConfig(cfg Config) retry.Config {
+	base := cfg.RetryBaseDelay
+	if base <= 0 {
+		base = 500 * time.Millisecond
+	}
+	maxDelay := cfg.RetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	return retry.Config{
+		Name:           "fetch-download",
+		MaxAttempts:    cfg.MaxRetries + 1,
+		InitialDelay:   base,
+		MaxDelay:       maxDelay,
+		BackoffFactor:  2.0,
+		JitterFraction: 0.1,
+	}
+}
+
+// downloadToFile streams targetURL into a temp file beside outPath, retrying
+// transient failures per cfg.MaxRetries/RetryBaseDelay/RetryMaxDelay and
+// resuming from where a previous attempt left off when the server supports
+// it, then returns the complete downloaded body for validateBody and the
+// last HTTP status observed (0 if no attempt ever got a response). On a
+// terminal or retries-exhausted failure, the temp file and its resume
+// sidecar are left in place rather than cleaned up here, so a later call
+// (another inner attempt, or a fresh Do from an outer retry loop) can
+// resume it; the caller decides what to do with them once validateBody has
+// run.
+func downloadToFile(ctx context.Context, cfg Config, targetURL, outPath string) ([]byte, int, error) {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return nil, 0, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	partPath, sidecarPath := partialPath(outPath), metaPath(outPath)
+	retryCfg := downloadRetryConfig(cfg)
+
+	var lastErr *downloadAttemptError
+	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := retry.Delay(retryCfg, attempt-1)
+			if lastErr.retryAfter > 0 {
+				delay = lastErr.retryAfter
+			}
+			select {
+			case <-ctx.Done():
+				return nil, 0, fmt.Errorf("context cancelled before download retry %d: %w", attempt+1, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		statusCode, attemptErr := attemptDownload(ctx, cfg, targetURL, partPath, sidecarPath)
+		if attemptErr == nil {
+			body, err := os.ReadFile(partPath)
+			if err != nil {
+				return nil, statusCode, fmt.Errorf("reading downloaded file: %w", err)
+			}
+			return body, statusCode, nil
+		}
+		if !attemptErr.retryable {
+			return nil, attemptErr.statusCode, attemptErr.err
+		}
+		lastErr = attemptErr
+	}
+
+	return nil, lastErr.statusCode, fmt.Errorf("download failed after %d attempts: %w", cfg.MaxRetries+1, lastErr.err)
+}
+
+// attemptDownload performs one GET (or resumed Range GET, if partPath
+// already holds a partial download with usable resume metadata) of
+// targetURL, streaming the response body into partPath and refreshing
+// sidecarPath as it goes. It returns the HTTP status observed (0 if the
+// request never got a response) alongside any attempt error.
+func attemptDownload(ctx context.Context, cfg Config, targetURL, partPath, sidecarPath string) (int, *downloadAttemptError) {
+	client, err := buildClient(cfg, targetURL)
+	if err != nil {
+		return 0, &downloadAttemptError{err: err}
+	}
+
+	var resumeFrom int64
+	var resuming bool
+	var meta downloadMeta
+	if info, err := os.Stat(partPath); err == nil && info.Size() > 0 {
+		if m, ok := readDownloadMeta(sidecarPath); ok && m.AcceptRanges {
+			resumeFrom, resuming, meta = info.Size(), true, m
+		} else {
+			// A partial file without usable resume metadata is stale (e.g.
+			// left over from a server that never advertised Accept-Ranges);
+			// start it over rather than guessing.
+			os.Remove(partPath)
+			os.Remove(sidecarPath)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return 0, &downloadAttemptError{err: fmt.Errorf("creating request: %w", err)}
+	}
+	authEnv := hostAuthEnv(cfg, targetURL)
+	if authEnv != "" {
+		authVal := os.Getenv(authEnv)
+		if authVal == "" {
+			return 0, &downloadAttemptError{err: fmt.Errorf("auth env var %q is empty or not set", authEnv)}
+		}
+		req.Header.Set("Authorization", authVal)
+	}
+	for k, v := range hostHeaders(cfg, targetURL) {
+		req.Header.Set(k, v)
+	}
+	if resuming {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+		if meta.ETag != "" {
+			req.Header.Set("If-Range", meta.ETag)
+		} else if meta.LastModified != "" {
+			req.Header.Set("If-Range", meta.LastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, &downloadAttemptError{err: fmt.Errorf("HTTP request to %s: %w", targetURL, err), retryable: true}
+	}
+	defer resp.Body.Close()
+
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, &downloadAttemptError{
+			err:        fmt.Errorf("HTTP %s returned status %d", targetURL, resp.StatusCode),
+			retryable:  true,
+			retryAfter: retryAfter,
+			statusCode: resp.StatusCode,
+		}
+	}
+	if err := checkStatusRange(cfg, targetURL, resp.StatusCode); err != nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, &downloadAttemptError{err: err, statusCode: resp.StatusCode}
+	}
+
+	// A server that ignores (or doesn't support) the Range request sends
+	// back a plain 200 with the full body; fall back to a fresh download
+	// rather than appending the full body onto what's already on disk.
+	appending := resuming && resp.StatusCode == http.StatusPartialContent
+	flag := os.O_CREATE | os.O_WRONLY
+	if appending {
+		flag |= os.O_APPEND
+	} else {
+		flag |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(partPath, flag, 0o644)
+	if err != nil {
+		return resp.StatusCode, &downloadAttemptError{err: fmt.Errorf("opening %s: %w", partPath, err), statusCode: resp.StatusCode}
+	}
+	defer f.Close()
+
+	if !appending {
+		writeDownloadMeta(sidecarPath, downloadMeta{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			AcceptRanges: resp.Header.Get("Accept-Ranges") == "bytes",
+		})
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return resp.StatusCode, &downloadAttemptError{err: fmt.Errorf("streaming response body: %w", err), retryable: true, statusCode: resp.StatusCode}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// readDownloadMeta loads a download sidecar, if present and valid.
+func readDownloadMeta(path string) (downloadMeta, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return downloadMeta{}, false
+	}
+	var m downloadMeta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return downloadMeta{}, false
+	}
+	return m, true
+}
+
+// writeDownloadMeta is best-effort: losing the sidecar only costs a future
+// resume opportunity, not correctness, since a missing/unreadable sidecar
+// just makes the next attempt start over.
+func writeDownloadMeta(path string, m downloadMeta) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, either a number
+// of seconds or an HTTP-date, returning 0 if it's empty or unparsable, or
+// names a time already in the past.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// finalizeDownload renames outPath's completed partial download into place
+// and removes its now-unneeded resume sidecar.
+func finalizeDownload(outPath string) error {
+	if err := os.Rename(partialPath(outPath), outPath); err != nil {
+		return fmt.Errorf("renaming downloaded file into place: %w", err)
+	}
+	os.Remove(metaPath(outPath))
+	return nil
+}
+
+// removeDownloadArtifacts discards a downloaded body that failed
+// validateBody. Resuming corrupt or unexpected content would only
+// perpetuate the problem, so nothing is kept for a future attempt to
+// resume from.
+func removeDownloadArtifacts(outPath string) {
+	os.Remove(partialPath(outPath))
+	os.Remove(metaPath(outPath))
+}
@@ -0,0 +1,264 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoRetriesOn503ThenSucceeds(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("recovered"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:            srv.URL,
+		OutputPath:     "out.txt",
+		Workdir:        workdir,
+		Timeout:        5 * time.Second,
+		MaxRetries:     2,
+		RetryBaseDelay: 10 * time.Millisecond,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected success after retries, got: %v", err)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+	if result.Bytes != len("recovered") {
+		t.Fatalf("unexpected Bytes: %d", result.Bytes)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.txt"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != "recovered" {
+		t.Fatalf("expected 'recovered', got %q", content)
+	}
+	if _, err := os.Stat(partialPath(filepath.Join(workdir, "out.txt"))); !os.IsNotExist(err) {
+		t.Fatalf("expected partial download file to be cleaned up, err=%v", err)
+	}
+}
+
+func TestDoExhaustsDownloadRetries(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:            srv.URL,
+		OutputPath:     "out.txt",
+		Workdir:        workdir,
+		Timeout:        5 * time.Second,
+		MaxRetries:     2,
+		RetryBaseDelay: 10 * time.Millisecond,
+	}
+
+	_, err := Do(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected error once download retries are exhausted")
+	}
+	if requests != 3 {
+		t.Fatalf("expected 1 initial attempt + 2 retries = 3 requests, got %d", requests)
+	}
+	if !strings.Contains(err.Error(), "status 502") {
+		t.Fatalf("expected status 502 in error, got: %v", err)
+	}
+}
+
+func TestDoDoesNotRetryNonRetryableStatus(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:            srv.URL,
+		OutputPath:     "out.txt",
+		Workdir:        workdir,
+		Timeout:        5 * time.Second,
+		MaxRetries:     3,
+		RetryBaseDelay: 10 * time.Millisecond,
+	}
+
+	_, err := Do(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if requests != 1 {
+		t.Fatalf("expected a 404 not to be retried, got %d requests", requests)
+	}
+}
+
+func TestDoResumesInterruptedDownload(t *testing.T) {
+	const fullBody = "0123456789abcdef"
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			// First attempt: send half the body, flush it onto the wire,
+			// then drop the connection to simulate a network interruption
+			// mid-transfer.
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(fullBody[:len(fullBody)/2]))
+			w.(http.Flusher).Flush()
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatal("expected ResponseWriter to support hijacking")
+			}
+			conn, _, err := hj.Hijack()
+			if err != nil {
+				t.Fatalf("hijacking connection: %v", err)
+			}
+			conn.Close()
+			return
+		}
+
+		if r.Header.Get("If-Range") != `"v1"` {
+			t.Fatalf("expected If-Range %q, got %q", `"v1"`, r.Header.Get("If-Range"))
+		}
+		offset := len(fullBody) / 2
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(fullBody[offset:]))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:            srv.URL,
+		OutputPath:     "out.bin",
+		Workdir:        workdir,
+		Timeout:        5 * time.Second,
+		MaxRetries:     1,
+		RetryBaseDelay: 10 * time.Millisecond,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected resumed download to succeed, got: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests (interrupted + resumed), got %d", requests)
+	}
+	if result.Bytes != len(fullBody) {
+		t.Fatalf("expected %d bytes, got %d", len(fullBody), result.Bytes)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.bin"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != fullBody {
+		t.Fatalf("expected %q, got %q", fullBody, string(content))
+	}
+}
+
+func TestDoExpectSizeMismatchDeletesPartial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	outPath := filepath.Join(workdir, "out.txt")
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		ExpectSize: 999,
+	}
+
+	_, err := Do(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected error for --expect-size mismatch")
+	}
+	if !strings.Contains(err.Error(), "--expect-size mismatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no output file to be written, err=%v", err)
+	}
+	if _, err := os.Stat(partialPath(outPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected partial download file to be removed on mismatch, err=%v", err)
+	}
+}
+
+func TestDoExpectSHA256MismatchDeletesPartial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	outPath := filepath.Join(workdir, "out.txt")
+	cfg := Config{
+		URL:          srv.URL,
+		OutputPath:   "out.txt",
+		Workdir:      workdir,
+		Timeout:      5 * time.Second,
+		ExpectSHA256: "deadbeef",
+	}
+
+	if _, err := Do(context.Background(), cfg); err == nil {
+		t.Fatal("expected error for --expect-sha256 mismatch")
+	}
+
+	if _, err := os.Stat(partialPath(outPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected partial download file to be removed on mismatch, err=%v", err)
+	}
+	if _, err := os.Stat(metaPath(outPath)); !os.IsNotExist(err) {
+		t.Fatalf("expected resume sidecar to be removed on mismatch, err=%v", err)
+	}
+}
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	if got := parseRetryAfter("5"); got != 5*time.Second {
+		t.Fatalf("expected 5s, got %s", got)
+	}
+}
+
+func TestParseRetryAfterEmpty(t *testing.T) {
+	if got := parseRetryAfter(""); got != 0 {
+		t.Fatalf("expected 0, got %s", got)
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	got := parseRetryAfter(future)
+	if got <= 0 || got > time.Hour {
+		t.Fatalf("expected a positive duration close to 1h, got %s", got)
+	}
+}
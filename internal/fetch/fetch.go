@@ -2,15 +2,20 @@ package fetch
 
 import (
 	"context"
-	"crypto/tls"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kitstream/initium/internal/jsonpath"
 	"github.com/kitstream/initium/internal/safety"
 )
 
@@ -23,6 +28,99 @@ type Config struct {
 	FollowRedirects        bool
 	AllowCrossSiteRedirect bool
 	Timeout                time.Duration
+
+	// ExpectStatusRange, e.g. "200-299"; defaults to "200-299" when empty.
+	ExpectStatusRange string
+	// ExpectBodyRegex, when set, must match somewhere in the response body.
+	ExpectBodyRegex string
+	// ExpectJSONPath and ExpectJSONValue, when both set, require the value
+	// found at ExpectJSONPath in the (JSON-decoded) body to stringify to
+	// ExpectJSONValue.
+	ExpectJSONPath  string
+	ExpectJSONValue string
+	// ExpectSHA256, when set, is the lowercase hex sha256 digest the
+	// downloaded body must match.
+	ExpectSHA256 string
+	// ExpectSize, when set, is the exact size in bytes the downloaded body
+	// must match.
+	ExpectSize int64
+
+	// MaxRetries, RetryBaseDelay, and RetryMaxDelay configure Do's own retry
+	// loop around the HTTP(S) download itself: it retries network errors and
+	// 5xx/429 responses with exponential backoff, honoring a Retry-After
+	// response header when present. This is independent of, and nested
+	// inside, whatever retry loop a caller wraps around the whole Do call
+	// (e.g. "initium fetch"'s --max-attempts). 0 MaxRetries (the default)
+	// disables this inner retry, leaving retries entirely to the caller, as
+	// before. The same loop resumes an interrupted download via Range/
+	// If-Range instead of restarting it, provided the server previously
+	// returned Accept-Ranges: bytes. Not used for CacheDir fetches, which
+	// have their own conditional-request and atomic-write handling.
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// CacheDir, when set, enables a content-addressable on-disk cache for
+	// HTTP(S) fetches, keyed by method, URL, auth fingerprint, and the
+	// Accept header. Conditional requests (ETag/Last-Modified) revalidate
+	// existing entries instead of re-downloading unchanged bodies.
+	CacheDir string
+	// CacheMaxAge, when set, skips conditional revalidation and forces a
+	// full fresh GET once an entry is older than this; 0 always revalidates.
+	CacheMaxAge time.Duration
+	// CacheMaxBytes, when set, evicts the least-recently-used cache entries
+	// after each write until CacheDir's total size is at or under this.
+	CacheMaxBytes int64
+	// Offline serves the cached body, if present, instead of failing when
+	// the HTTP request itself errors out (e.g. DNS or connection failure).
+	Offline bool
+
+	// Format controls how the fetched body is written: "" and "raw" (the
+	// default) write it unchanged, "json" re-marshals it indented, and
+	// "dotenv" flattens a JSON object's top-level keys into KEY=VALUE
+	// lines for a subsequent `initium exec --env-file`.
+	Format string
+
+	// Adapters registers TransferAdapters by URL scheme (e.g. "s3", "gs",
+	// "oci"), consulted before the secret-provider registry or any HTTP(S)
+	// handling. A nil Adapters (no --custom-adapter flags given) falls
+	// through to HTTP(S)/secret-provider handling as before.
+	Adapters *Manifest
+
+	// Sources lists additional HTTP(S) mirror URLs tried, in order, if URL
+	// (tried first) and each preceding Sources entry fails -- including
+	// exhausting that source's own MaxRetries. Only used for a plain
+	// HTTP(S) fetch (not a secret-provider scheme, a TransferAdapter
+	// scheme, or a CacheDir fetch, each of which always use URL alone).
+	Sources []string
+	// HostPolicy overrides AuthEnv, TLS, and request headers per host (as
+	// in url.URL.Host, including any port) across URL/Sources, so a
+	// multi-mirror fetch can use different credentials or CA bundles per
+	// mirror instead of one AuthEnv/InsecureTLS for all of them. A host
+	// with no entry falls back to Config's own AuthEnv and InsecureTLS.
+	HostPolicy map[string]HostRule
+}
+
+// Result reports how a fetch was actually served.
+type Result struct {
+	// Bytes is the number of bytes written to OutputPath.
+	Bytes int
+	// FromCache is true when the body came from CacheDir rather than a
+	// fresh download (a 304 revalidation or an --offline fallback).
+	FromCache bool
+	// CacheOffline is true when FromCache is true because the HTTP request
+	// failed and the cached body was served per --offline.
+	CacheOffline bool
+	// SecretMeta carries provider-specific metadata from a secret-provider
+	// fetch (currently just Vault's "lease_duration", in seconds); nil for
+	// HTTP(S) fetches and providers with nothing to report.
+	SecretMeta map[string]string
+	// Attempts records one SourceAttempt per URL/Sources entry tried by a
+	// plain HTTP(S) fetch (including the one that succeeded), so the cmd
+	// layer can log structured attempt/host/status fields without
+	// internal/fetch depending on internal/logging. Nil for secret-provider,
+	// adapter, and CacheDir fetches, which always use a single URL.
+	Attempts []SourceAttempt
 }
 
 func (c Config) Validate() error {
@@ -35,64 +133,291 @@ func (c Config) Validate() error {
 	if c.AllowCrossSiteRedirect && !c.FollowRedirects {
 		return fmt.Errorf("--allow-cross-site-redirects requires --follow-redirects")
 	}
+	if c.ExpectStatusRange != "" {
+		if _, _, err := parseStatusRange(c.ExpectStatusRange); err != nil {
+			return fmt.Errorf("invalid --expect-status-range: %w", err)
+		}
+	}
+	if c.ExpectBodyRegex != "" {
+		if _, err := regexp.Compile(c.ExpectBodyRegex); err != nil {
+			return fmt.Errorf("invalid --expect-body-regex: %w", err)
+		}
+	}
+	if (c.ExpectJSONPath == "") != (c.ExpectJSONValue == "") {
+		return fmt.Errorf("--expect-json-path and --expect-json-value must be set together")
+	}
+	if c.ExpectSize < 0 {
+		return fmt.Errorf("--expect-size must be >= 0, got %d", c.ExpectSize)
+	}
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("--max-download-retries must be >= 0, got %d", c.MaxRetries)
+	}
+	if c.RetryBaseDelay < 0 {
+		return fmt.Errorf("--retry-base-delay must be >= 0, got %s", c.RetryBaseDelay)
+	}
+	if c.RetryMaxDelay < 0 {
+		return fmt.Errorf("--retry-max-delay must be >= 0, got %s", c.RetryMaxDelay)
+	}
+	switch c.Format {
+	case "", "raw", "json", "dotenv":
+	default:
+		return fmt.Errorf("--format must be raw, json, or dotenv, got %q", c.Format)
+	}
+	if c.CacheDir == "" {
+		if c.CacheMaxAge != 0 {
+			return fmt.Errorf("--cache-max-age requires --cache-dir")
+		}
+		if c.CacheMaxBytes != 0 {
+			return fmt.Errorf("--cache-max-bytes requires --cache-dir")
+		}
+		if c.Offline {
+			return fmt.Errorf("--offline requires --cache-dir")
+		}
+	}
+	for host, rule := range c.HostPolicy {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("--host-rule for %s: %w", host, err)
+		}
+	}
 	return nil
 }
 
-func Do(ctx context.Context, cfg Config) error {
+// parseStatusRange parses a "min-max" string like "200-299" into bounds.
+func parseStatusRange(s string) (min, max int, err error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected format MIN-MAX, got %q", s)
+	}
+	min, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid min status %q: %w", parts[0], err)
+	}
+	max, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid max status %q: %w", parts[1], err)
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("min status %d is greater than max status %d", min, max)
+	}
+	return min, max, nil
+}
+
+func Do(ctx context.Context, cfg Config) (Result, error) {
 	if err := cfg.Validate(); err != nil {
-		return err
+		return Result{}, err
 	}
 
 	outPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.OutputPath)
 	if err != nil {
-		return fmt.Errorf("invalid output path: %w", err)
+		return Result{}, fmt.Errorf("invalid output path: %w", err)
+	}
+
+	parsedURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing url %q: %w", cfg.URL, err)
 	}
 
-	client := buildClient(cfg)
+	if adapter, ok := cfg.Adapters.Lookup(parsedURL.Scheme); ok {
+		body, err := fetchViaAdapter(ctx, cfg, adapter, outPath)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if err := validateBody(cfg, body); err != nil {
+			removeDownloadArtifacts(outPath)
+			return Result{}, err
+		}
+
+		if err := finalizeDownload(outPath); err != nil {
+			return Result{}, err
+		}
+		recordBytesFetched(ctx, len(body))
+		return Result{Bytes: len(body)}, nil
+	}
+
+	if fetcher, ok := secretProviderRegistry[parsedURL.Scheme]; ok {
+		secret, err := fetcher(ctx, parsedURL, cfg)
+		if err != nil {
+			return Result{}, fmt.Errorf("fetching secret from %s: %w", cfg.URL, err)
+		}
+
+		body, err := selectSecretField(secret.Value, parsedURL)
+		if err != nil {
+			return Result{}, err
+		}
+		if body, err = formatSecretBody(body, cfg.Format); err != nil {
+			return Result{}, err
+		}
+
+		if err := validateBody(cfg, body); err != nil {
+			return Result{}, err
+		}
+		// Secret-provider output holds live credentials, so it is written
+		// atomically (via a temp file + rename) with owner-only perms,
+		// unlike the 0644 used for ordinary HTTP downloads.
+		if err := safety.WriteFileAtomic(outPath, body, 0o600); err != nil {
+			return Result{}, err
+		}
+		recordBytesFetched(ctx, len(body))
+		return Result{Bytes: len(body), SecretMeta: secret.Meta}, nil
+	}
+
+	if cfg.CacheDir != "" {
+		body, cr, err := fetchHTTPCached(ctx, cfg)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if err := validateBody(cfg, body); err != nil {
+			return Result{}, err
+		}
+
+		if err := safety.WriteFileAtomic(outPath, body, 0o644); err != nil {
+			return Result{}, err
+		}
+		recordBytesFetched(ctx, len(body))
+		return Result{Bytes: len(body), FromCache: cr.fromCache, CacheOffline: cr.offline}, nil
+	}
+
+	body, attempts, err := downloadWithSources(ctx, cfg, outPath)
+	if err != nil {
+		return Result{Attempts: attempts}, err
+	}
+
+	if err := validateBody(cfg, body); err != nil {
+		// The downloaded content is wrong or corrupt, so keeping the partial
+		// file around for a future resume would only perpetuate that;
+		// discard it and make the next attempt start clean.
+		removeDownloadArtifacts(outPath)
+		return Result{Attempts: attempts}, err
+	}
+
+	if err := finalizeDownload(outPath); err != nil {
+		return Result{Attempts: attempts}, err
+	}
+	recordBytesFetched(ctx, len(body))
+	return Result{Bytes: len(body), Attempts: attempts}, nil
+}
+
+// doHTTPRequest performs a single GET of cfg.URL, adding any condHeaders
+// (used for conditional cache revalidation) on top of the usual auth
+// header. The response's body is always fully read and returned alongside
+// resp so callers can inspect status codes (including 304, which this
+// function does not itself treat as an error) before validating them.
+func doHTTPRequest(ctx context.Context, cfg Config, condHeaders map[string]string) (*http.Response, []byte, error) {
+	client, err := buildClient(cfg, cfg.URL)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, nil, fmt.Errorf("creating request: %w", err)
 	}
 
-	if cfg.AuthEnv != "" {
-		authVal := os.Getenv(cfg.AuthEnv)
+	authEnv := hostAuthEnv(cfg, cfg.URL)
+	if authEnv != "" {
+		authVal := os.Getenv(authEnv)
 		if authVal == "" {
-			return fmt.Errorf("auth env var %q is empty or not set", cfg.AuthEnv)
+			return nil, nil, fmt.Errorf("auth env var %q is empty or not set", authEnv)
 		}
 		req.Header.Set("Authorization", authVal)
 	}
+	for k, v := range hostHeaders(cfg, cfg.URL) {
+		req.Header.Set(k, v)
+	}
+	for k, v := range condHeaders {
+		req.Header.Set(k, v)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request to %s: %w", cfg.URL, err)
+		return nil, nil, fmt.Errorf("HTTP request to %s: %w", cfg.URL, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("HTTP %s returned status %d", cfg.URL, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response body: %w", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return resp, body, nil
+}
+
+// checkStatusRange returns an error unless statusCode falls within
+// cfg.ExpectStatusRange (default "200-299"); targetURL is used only to name
+// the offending request in the error message.
+func checkStatusRange(cfg Config, targetURL string, statusCode int) error {
+	statusRange := cfg.ExpectStatusRange
+	if statusRange == "" {
+		statusRange = "200-299"
+	}
+	minStatus, maxStatus, err := parseStatusRange(statusRange)
 	if err != nil {
-		return fmt.Errorf("reading response body: %w", err)
+		return fmt.Errorf("invalid --expect-status-range: %w", err)
+	}
+	if statusCode < minStatus || statusCode > maxStatus {
+		return fmt.Errorf("HTTP %s returned status %d, expected %s", targetURL, statusCode, statusRange)
+	}
+	return nil
+}
+
+// validateBody applies the optional body-level checks (regex, JSON path,
+// checksum) configured on cfg. A mismatch here triggers a retry through the
+// same retry.Do loop that covers network and status errors, so e.g. "HTTP
+// 200 but body says not ready" is treated as not-ready rather than success.
+func validateBody(cfg Config, body []byte) error {
+	if cfg.ExpectBodyRegex != "" {
+		re, err := regexp.Compile(cfg.ExpectBodyRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --expect-body-regex: %w", err)
+		}
+		if !re.Match(body) {
+			return fmt.Errorf("response body did not match --expect-body-regex %q", cfg.ExpectBodyRegex)
+		}
 	}
 
-	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
-		return fmt.Errorf("creating output directory: %w", err)
+	if cfg.ExpectJSONPath != "" {
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			return fmt.Errorf("response body is not valid JSON for --expect-json-path: %w", err)
+		}
+		val, err := jsonpath.Eval(decoded, cfg.ExpectJSONPath)
+		if err != nil {
+			return fmt.Errorf("evaluating --expect-json-path %q: %w", cfg.ExpectJSONPath, err)
+		}
+		got := fmt.Sprintf("%v", val)
+		if got != cfg.ExpectJSONValue {
+			return fmt.Errorf("--expect-json-path %q: got %q, want %q", cfg.ExpectJSONPath, got, cfg.ExpectJSONValue)
+		}
 	}
 
-	if err := os.WriteFile(outPath, body, 0o644); err != nil {
-		return fmt.Errorf("writing output %s: %w", outPath, err)
+	if cfg.ExpectSHA256 != "" {
+		sum := sha256.Sum256(body)
+		got := hex.EncodeToString(sum[:])
+		if !strings.EqualFold(got, cfg.ExpectSHA256) {
+			return fmt.Errorf("--expect-sha256 mismatch: got %s, want %s", got, cfg.ExpectSHA256)
+		}
+	}
+
+	if cfg.ExpectSize > 0 && int64(len(body)) != cfg.ExpectSize {
+		return fmt.Errorf("--expect-size mismatch: got %d bytes, want %d", len(body), cfg.ExpectSize)
 	}
 
 	return nil
 }
 
-func buildClient(cfg Config) *http.Client {
+// buildClient constructs the *http.Client used to fetch targetURL: its TLS
+// config comes from the matching HostRule (client cert, CA bundle, insecure
+// flag), falling back to cfg.InsecureTLS when targetURL's host has no rule.
+func buildClient(cfg Config, targetURL string) (*http.Client, error) {
 	transport := &http.Transport{}
-	if cfg.InsecureTLS {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // user-opt-in via --insecure-tls
+	tlsCfg, err := hostTLSConfig(cfg, targetURL)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
 	}
 
 	client := &http.Client{
@@ -108,7 +433,7 @@ func buildClient(cfg Config) *http.Client {
 		client.CheckRedirect = sameSiteRedirectPolicy
 	}
 
-	return client
+	return client, nil
 }
 
 func sameSiteRedirectPolicy(req *http.Request, via []*http.Request) error {
@@ -26,7 +26,7 @@ func TestDoSuccess(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("expected success, got: %v", err)
 	}
@@ -60,7 +60,7 @@ func TestDoAuthHeader(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("expected success, got: %v", err)
 	}
@@ -87,7 +87,7 @@ func TestDoAuthEnvEmpty(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for empty auth env var")
 	}
@@ -103,7 +103,7 @@ func TestDoMissingURL(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for missing URL")
 	}
@@ -119,7 +119,7 @@ func TestDoMissingOutput(t *testing.T) {
 		Timeout: 5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for missing output")
 	}
@@ -142,7 +142,7 @@ func TestDoPathTraversal(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for path traversal")
 	}
@@ -165,7 +165,7 @@ func TestDoHTTPError(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for 500 status")
 	}
@@ -191,14 +191,14 @@ func TestDoInsecureTLS(t *testing.T) {
 		InsecureTLS: false,
 		Timeout:     5 * time.Second,
 	}
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for self-signed cert without insecure-tls")
 	}
 
 	// With insecure TLS: should succeed
 	cfg.InsecureTLS = true
-	err = Do(context.Background(), cfg)
+	_, err = Do(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("expected success with insecure-tls, got: %v", err)
 	}
@@ -233,7 +233,7 @@ func TestDoNoFollowRedirects(t *testing.T) {
 	}
 
 	// Without follow-redirects, a 302 is a non-2xx status → error
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err == nil {
 		t.Fatal("expected error for redirect without follow-redirects")
 	}
@@ -262,7 +262,7 @@ func TestDoFollowRedirectsSameSite(t *testing.T) {
 		Timeout:         5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("expected success for same-site redirect, got: %v", err)
 	}
@@ -291,7 +291,7 @@ func TestDoNestedOutputDir(t *testing.T) {
 		Timeout:    5 * time.Second,
 	}
 
-	err := Do(context.Background(), cfg)
+	_, err := Do(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("expected success, got: %v", err)
 	}
@@ -323,7 +323,7 @@ func TestDoContextCancelled(t *testing.T) {
 		Timeout:    10 * time.Second,
 	}
 
-	err := Do(ctx, cfg)
+	_, err := Do(ctx, cfg)
 	if err == nil {
 		t.Fatal("expected error for cancelled context")
 	}
@@ -346,3 +346,136 @@ func TestValidateAllowCrossSiteWithoutFollowRedirects(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestDoExpectJSONPathMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ready":false}`))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:             srv.URL,
+		OutputPath:      "out.json",
+		Workdir:         workdir,
+		Timeout:         5 * time.Second,
+		ExpectJSONPath:  "ready",
+		ExpectJSONValue: "true",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := Do(ctx, cfg); err == nil {
+		t.Fatal("expected error when JSON path value does not match")
+	}
+}
+
+func TestDoExpectJSONPathMatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ready":true}`))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:             srv.URL,
+		OutputPath:      "out.json",
+		Workdir:         workdir,
+		Timeout:         5 * time.Second,
+		ExpectJSONPath:  "ready",
+		ExpectJSONValue: "true",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := Do(ctx, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDoExpectBodyRegexMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("pending"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:             srv.URL,
+		OutputPath:      "out.txt",
+		Workdir:         workdir,
+		Timeout:         5 * time.Second,
+		ExpectBodyRegex: `^ready$`,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := Do(ctx, cfg); err == nil {
+		t.Fatal("expected error when body does not match regex")
+	}
+}
+
+func TestDoExpectSHA256Mismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:          srv.URL,
+		OutputPath:   "out.txt",
+		Workdir:      workdir,
+		Timeout:      5 * time.Second,
+		ExpectSHA256: "deadbeef",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := Do(ctx, cfg); err == nil {
+		t.Fatal("expected error when sha256 does not match")
+	}
+}
+
+func TestValidateExpectStatusRangeInvalid(t *testing.T) {
+	cfg := Config{
+		URL:               "http://example.com",
+		OutputPath:        "out.txt",
+		Workdir:           "/tmp",
+		ExpectStatusRange: "not-a-range",
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for invalid --expect-status-range")
+	}
+}
+
+func TestValidateFormatInvalid(t *testing.T) {
+	cfg := Config{
+		URL:        "http://example.com",
+		OutputPath: "out.txt",
+		Workdir:    "/tmp",
+		Format:     "xml",
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for invalid --format")
+	}
+}
+
+func TestValidateFormatValid(t *testing.T) {
+	for _, format := range []string{"", "raw", "json", "dotenv"} {
+		cfg := Config{URL: "http://example.com", OutputPath: "out.txt", Workdir: "/tmp", Format: format}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error for --format %q: %v", format, err)
+		}
+	}
+}
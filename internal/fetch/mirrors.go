@@ -0,0 +1,160 @@
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// HostRule overrides AuthEnv, TLS, and request headers for one host (as in
+// url.URL.Host, including any port) in Config.HostPolicy, so a multi-mirror
+// fetch can use different credentials or CA bundles per mirror instead of
+// one AuthEnv/InsecureTLS for all of them.
+type HostRule struct {
+	// AuthEnv, when set, overrides Config.AuthEnv for this host.
+	AuthEnv string
+	// CACertFile, when set, is a PEM bundle used instead of the system root
+	// pool to verify this host's certificate.
+	CACertFile string
+	// ClientCertFile and ClientKeyFile, when set, must both be set and are
+	// presented as a client certificate for mutual TLS to this host.
+	ClientCertFile string
+	ClientKeyFile  string
+	// InsecureTLS, when true, overrides Config.InsecureTLS for this host.
+	InsecureTLS bool
+	// Headers are added to every request to this host, on top of AuthEnv's
+	// Authorization header.
+	Headers map[string]string
+}
+
+func (r HostRule) Validate() error {
+	if (r.ClientCertFile == "") != (r.ClientKeyFile == "") {
+		return fmt.Errorf("client-cert-file and client-key-file must be set together")
+	}
+	return nil
+}
+
+// hostRuleFor looks up the HostRule for targetURL's host, if any.
+func hostRuleFor(cfg Config, targetURL string) HostRule {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return HostRule{}
+	}
+	return cfg.HostPolicy[u.Host]
+}
+
+// hostAuthEnv resolves the auth env var to use for targetURL: the matching
+// HostRule's AuthEnv if set, else cfg.AuthEnv.
+func hostAuthEnv(cfg Config, targetURL string) string {
+	if rule := hostRuleFor(cfg, targetURL); rule.AuthEnv != "" {
+		return rule.AuthEnv
+	}
+	return cfg.AuthEnv
+}
+
+// hostHeaders returns the extra headers a HostRule wants added to every
+// request to targetURL's host; nil if none are configured.
+func hostHeaders(cfg Config, targetURL string) map[string]string {
+	return hostRuleFor(cfg, targetURL).Headers
+}
+
+// hostTLSConfig builds the *tls.Config to use for targetURL: InsecureSkipVerify
+// per the matching HostRule (falling back to cfg.InsecureTLS), a custom root
+// pool from CACertFile if set, and a client certificate from
+// ClientCertFile/ClientKeyFile if set. It returns nil if none of those apply,
+// so buildClient can leave the transport's TLSClientConfig at its zero value.
+func hostTLSConfig(cfg Config, targetURL string) (*tls.Config, error) {
+	rule := hostRuleFor(cfg, targetURL)
+
+	insecure := cfg.InsecureTLS || rule.InsecureTLS
+	if !insecure && rule.CACertFile == "" && rule.ClientCertFile == "" {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{InsecureSkipVerify: insecure} //nolint:gosec // user-opt-in via --insecure-tls or a host rule
+
+	if rule.CACertFile != "" {
+		pem, err := os.ReadFile(rule.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca-cert-file %s: %w", rule.CACertFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca-cert-file %s", rule.CACertFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if rule.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(rule.ClientCertFile, rule.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client cert/key for %s: %w", targetURL, err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// SourceAttempt records the outcome of one source (Config.URL or a
+// Config.Sources entry) tried by a multi-source fetch, so the cmd layer can
+// log structured attempt/host/status fields without internal/fetch
+// depending on internal/logging.
+type SourceAttempt struct {
+	// Source is the URL tried.
+	Source string
+	// Host is Source's host, as in url.URL.Host.
+	Host string
+	// StatusCode is the HTTP status returned, or 0 if the request never got
+	// a response (DNS failure, connection refused, context cancellation).
+	StatusCode int
+	// Err is the attempt's failure reason, or "" if it succeeded.
+	Err string
+}
+
+// effectiveSources returns the ordered list of URLs a fetch should try:
+// cfg.URL (if set) first, then cfg.Sources in order.
+func effectiveSources(cfg Config) []string {
+	sources := make([]string, 0, 1+len(cfg.Sources))
+	if cfg.URL != "" {
+		sources = append(sources, cfg.URL)
+	}
+	sources = append(sources, cfg.Sources...)
+	return sources
+}
+
+func hostFromTargetURL(targetURL string) string {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// downloadWithSources tries each of effectiveSources(cfg) in turn, moving on
+// to the next one once a source's own inner retry loop (MaxRetries/
+// RetryBaseDelay/RetryMaxDelay) is exhausted or fails terminally. It returns
+// the winning source's body alongside one SourceAttempt per source tried
+// (including the winner), and a MaxRetries-exhausted-style error listing the
+// last source's failure if every source failed.
+func downloadWithSources(ctx context.Context, cfg Config, outPath string) ([]byte, []SourceAttempt, error) {
+	sources := effectiveSources(cfg)
+
+	var attempts []SourceAttempt
+	var lastErr error
+	for _, source := range sources {
+		body, statusCode, err := downloadToFile(ctx, cfg, source, outPath)
+		host := hostFromTargetURL(source)
+		if err == nil {
+			attempts = append(attempts, SourceAttempt{Source: source, Host: host, StatusCode: statusCode})
+			return body, attempts, nil
+		}
+		attempts = append(attempts, SourceAttempt{Source: source, Host: host, StatusCode: statusCode, Err: err.Error()})
+		lastErr = err
+	}
+
+	return nil, attempts, fmt.Errorf("all %d source(s) failed, last error: %w", len(sources), lastErr)
+}
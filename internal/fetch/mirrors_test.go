@@ -0,0 +1,192 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDoFallsBackToMirrorOn503(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("from mirror"))
+	}))
+	defer mirror.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        primary.URL,
+		Sources:    []string{mirror.URL},
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected mirror fallback to succeed, got: %v", err)
+	}
+	if len(result.Attempts) != 2 {
+		t.Fatalf("expected 2 attempts (primary + mirror), got %d", len(result.Attempts))
+	}
+	if result.Attempts[0].StatusCode != http.StatusServiceUnavailable || result.Attempts[0].Err == "" {
+		t.Fatalf("expected first attempt to record the 503 failure, got %+v", result.Attempts[0])
+	}
+	if result.Attempts[1].StatusCode != http.StatusOK || result.Attempts[1].Err != "" {
+		t.Fatalf("expected second attempt to record success, got %+v", result.Attempts[1])
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.txt"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != "from mirror" {
+		t.Fatalf("expected 'from mirror', got %q", content)
+	}
+}
+
+func TestDoAllSourcesFail(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer mirror.Close()
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        primary.URL,
+		Sources:    []string{mirror.URL},
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+	}
+
+	_, err := Do(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected error when all sources fail")
+	}
+}
+
+func TestHostRuleAuthEnvOverridesConfigAuthEnv(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing test server URL: %v", err)
+	}
+
+	t.Setenv("GLOBAL_AUTH", "Bearer global")
+	t.Setenv("HOST_AUTH", "Bearer host-specific")
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		AuthEnv:    "GLOBAL_AUTH",
+		Timeout:    5 * time.Second,
+		HostPolicy: map[string]HostRule{
+			u.Host: {AuthEnv: "HOST_AUTH"},
+		},
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+	if gotAuth != "Bearer host-specific" {
+		t.Fatalf("expected host-specific auth header, got %q", gotAuth)
+	}
+}
+
+func TestHostRuleHeadersAreAdded(t *testing.T) {
+	var gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Mirror-Token")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing test server URL: %v", err)
+	}
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		HostPolicy: map[string]HostRule{
+			u.Host: {Headers: map[string]string{"X-Mirror-Token": "xyz"}},
+		},
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+	if gotHeader != "xyz" {
+		t.Fatalf("expected header to be set, got %q", gotHeader)
+	}
+}
+
+func TestHostRuleInsecureTLSAppliesPerHost(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parsing test server URL: %v", err)
+	}
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        srv.URL,
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Timeout:    5 * time.Second,
+		HostPolicy: map[string]HostRule{
+			u.Host: {InsecureTLS: true},
+		},
+	}
+
+	if _, err := Do(context.Background(), cfg); err != nil {
+		t.Fatalf("expected success with per-host insecure-tls, got: %v", err)
+	}
+}
+
+func TestHostRuleClientCertKeyMustBeSetTogether(t *testing.T) {
+	cfg := Config{
+		URL:        "http://example.com",
+		OutputPath: "out.txt",
+		HostPolicy: map[string]HostRule{
+			"example.com": {ClientCertFile: "cert.pem"},
+		},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for client-cert-file without client-key-file")
+	}
+}
@@ -0,0 +1,364 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	secretmanagerpb "cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	vaultapi "github.com/hashicorp/vault/api"
+	"google.golang.org/api/option"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kitstream/initium/internal/jsonpath"
+)
+
+// secretResult is what a secretFetcher returns: the raw secret value, plus
+// any provider metadata worth surfacing to the caller (currently just
+// Vault's lease_duration). Meta is nil when a provider has nothing to add.
+type secretResult struct {
+	Value []byte
+	Meta  map[string]string
+}
+
+// secretFetcher fetches a secret identified by a URL (whose scheme has
+// already been matched against secretProviderRegistry) and returns its raw
+// value. Each provider authenticates using whatever ambient credentials its
+// platform makes available by default; cfg.AuthEnv, when set, names an env
+// var holding provider-specific credentials instead (a Vault token, a JSON
+// blob of AWS static credentials, or a path to a GCP service account key),
+// so no provider-specific flags are needed on `initium fetch`.
+type secretFetcher func(ctx context.Context, u *url.URL, cfg Config) (secretResult, error)
+
+var secretProviderRegistry = map[string]secretFetcher{
+	"vault":      fetchVaultSecret,
+	"aws-sm":     fetchAWSSecret,
+	"gcp-sm":     fetchGCPSecret,
+	"k8s-secret": fetchK8sSecret,
+}
+
+// selectSecretField extracts a single value out of a JSON secret body using
+// the URL fragment (vault://secret/data/app#password) or a ?field= query
+// parameter (aws-sm://prod/db/creds?field=password) as a jsonpath into the
+// decoded body. A string result is returned verbatim; any other JSON value
+// is re-marshalled. A URL with neither returns body unchanged.
+func selectSecretField(body []byte, u *url.URL) ([]byte, error) {
+	path := u.Fragment
+	if path == "" {
+		path = u.Query().Get("field")
+	}
+	if path == "" {
+		return body, nil
+	}
+
+	var data any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("decoding secret as JSON to select field %q: %w", path, err)
+	}
+	value, err := jsonpath.Eval(data, path)
+	if err != nil {
+		return nil, fmt.Errorf("selecting field %q: %w", path, err)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling selected field %q: %w", path, err)
+	}
+	return out, nil
+}
+
+// formatSecretBody renders body per --format: "" and "raw" pass it through
+// unchanged, "json" re-marshals it indented, and "dotenv" flattens a JSON
+// object's top-level keys into KEY=VALUE lines (uppercased).
+func formatSecretBody(body []byte, format string) ([]byte, error) {
+	switch format {
+	case "", "raw":
+		return body, nil
+	case "json":
+		var v any
+		if err := json.Unmarshal(body, &v); err != nil {
+			return nil, fmt.Errorf("formatting secret as json: %w", err)
+		}
+		out, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("formatting secret as json: %w", err)
+		}
+		return out, nil
+	case "dotenv":
+		var m map[string]any
+		if err := json.Unmarshal(body, &m); err != nil {
+			return nil, fmt.Errorf("formatting secret as dotenv: body is not a JSON object: %w", err)
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var buf strings.Builder
+		for _, k := range keys {
+			fmt.Fprintf(&buf, "%s=%s\n", strings.ToUpper(k), dotenvValue(m[k]))
+		}
+		return []byte(buf.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown --format %q: must be raw, json, or dotenv", format)
+	}
+}
+
+// dotenvValue renders a decoded JSON value as a dotenv-safe string,
+// quoting it when it contains whitespace so the line stays one field.
+func dotenvValue(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		s = string(out)
+	}
+	if strings.ContainsAny(s, " \t\n\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// awsStaticCredentials is the shape of the JSON blob --auth-env points at
+// for AWS static credentials, an alternative to the default credential
+// chain (IRSA) for environments without it.
+type awsStaticCredentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+}
+
+// vaultSecretPath turns vault://secret/data/myapp/config into the Vault API
+// path "secret/data/myapp/config".
+func vaultSecretPath(u *url.URL) (string, error) {
+	path := strings.Trim(u.Host+u.Path, "/")
+	if path == "" {
+		return "", fmt.Errorf("vault URL must include a secret path, got %q", u.String())
+	}
+	return path, nil
+}
+
+// fetchVaultSecret authenticates to Vault using cfg.AuthEnv (a Vault token)
+// if set, falling back to VAULT_TOKEN, then an AppRole login via
+// VAULT_ROLE_ID/VAULT_SECRET_ID, then reads the secret. KV v2 responses
+// nest the actual data under a "data" key; that nesting is unwrapped so
+// callers see the same shape for KV v1 and v2. The lease duration Vault
+// returned is reported in Meta so callers can log it without this package
+// depending on internal/logging.
+func fetchVaultSecret(ctx context.Context, u *url.URL, cfg Config) (secretResult, error) {
+	path, err := vaultSecretPath(u)
+	if err != nil {
+		return secretResult{}, err
+	}
+
+	client, err := vaultapi.NewClient(vaultapi.DefaultConfig())
+	if err != nil {
+		return secretResult{}, fmt.Errorf("creating vault client: %w", err)
+	}
+
+	if cfg.AuthEnv != "" {
+		token := os.Getenv(cfg.AuthEnv)
+		if token == "" {
+			return secretResult{}, fmt.Errorf("vault auth env var %q is empty or not set", cfg.AuthEnv)
+		}
+		client.SetToken(token)
+	} else if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		client.SetToken(token)
+	} else if roleID, secretID := os.Getenv("VAULT_ROLE_ID"), os.Getenv("VAULT_SECRET_ID"); roleID != "" && secretID != "" {
+		secret, err := client.Logical().WriteWithContext(ctx, "auth/approle/login", map[string]interface{}{
+			"role_id":   roleID,
+			"secret_id": secretID,
+		})
+		if err != nil {
+			return secretResult{}, fmt.Errorf("vault AppRole login: %w", err)
+		}
+		if secret == nil || secret.Auth == nil {
+			return secretResult{}, fmt.Errorf("vault AppRole login returned no auth info")
+		}
+		client.SetToken(secret.Auth.ClientToken)
+	} else {
+		return secretResult{}, fmt.Errorf("no vault credentials: set --auth-env, VAULT_TOKEN, or VAULT_ROLE_ID/VAULT_SECRET_ID")
+	}
+
+	secret, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return secretResult{}, fmt.Errorf("reading vault secret %s: %w", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return secretResult{}, fmt.Errorf("vault secret %s not found", path)
+	}
+
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return secretResult{}, fmt.Errorf("marshalling vault secret data: %w", err)
+	}
+
+	var meta map[string]string
+	if secret.LeaseDuration > 0 {
+		meta = map[string]string{"lease_duration": strconv.Itoa(secret.LeaseDuration)}
+	}
+	return secretResult{Value: body, Meta: meta}, nil
+}
+
+// awsSecretID turns aws-sm://prod/db/password into the secret ID
+// "prod/db/password".
+func awsSecretID(u *url.URL) (string, error) {
+	id := strings.Trim(u.Host+u.Path, "/")
+	if id == "" {
+		return "", fmt.Errorf("aws-sm URL must include a secret ID, got %q", u.String())
+	}
+	return id, nil
+}
+
+// fetchAWSSecret uses the default AWS credential chain, which picks up IRSA
+// (IAM Roles for Service Accounts) automatically via the
+// AWS_WEB_IDENTITY_TOKEN_FILE/AWS_ROLE_ARN env vars that EKS injects. If
+// cfg.AuthEnv is set, it instead names an env var holding a JSON blob of
+// static credentials ({"access_key_id", "secret_access_key",
+// "session_token"}), for environments without IRSA.
+func fetchAWSSecret(ctx context.Context, u *url.URL, cfg Config) (secretResult, error) {
+	secretID, err := awsSecretID(u)
+	if err != nil {
+		return secretResult{}, err
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.AuthEnv != "" {
+		raw := os.Getenv(cfg.AuthEnv)
+		if raw == "" {
+			return secretResult{}, fmt.Errorf("AWS auth env var %q is empty or not set", cfg.AuthEnv)
+		}
+		var staticCreds awsStaticCredentials
+		if err := json.Unmarshal([]byte(raw), &staticCreds); err != nil {
+			return secretResult{}, fmt.Errorf("parsing AWS auth env var %q as JSON: %w", cfg.AuthEnv, err)
+		}
+		opts = append(opts, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			staticCreds.AccessKeyID, staticCreds.SecretAccessKey, staticCreds.SessionToken,
+		)))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return secretResult{}, fmt.Errorf("loading AWS config: %w", err)
+	}
+
+	out, err := secretsmanager.NewFromConfig(awsCfg).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return secretResult{}, fmt.Errorf("fetching AWS secret %s: %w", secretID, err)
+	}
+
+	if out.SecretString != nil {
+		return secretResult{Value: []byte(*out.SecretString)}, nil
+	}
+	return secretResult{Value: out.SecretBinary}, nil
+}
+
+// gcpSecretName turns gcp-sm://projects/x/secrets/y/versions/latest into the
+// fully qualified resource name "projects/x/secrets/y/versions/latest".
+func gcpSecretName(u *url.URL) (string, error) {
+	name := strings.Trim(u.Host+u.Path, "/")
+	if name == "" {
+		return "", fmt.Errorf("gcp-sm URL must include a secret version resource name, got %q", u.String())
+	}
+	return name, nil
+}
+
+// fetchGCPSecret authenticates via Application Default Credentials, which
+// resolves to the attached workload identity when running on GKE. If
+// cfg.AuthEnv is set, it instead names an env var holding the path to a
+// service account JSON key file, for environments without workload
+// identity. name's final path segment selects a version, "latest" or a
+// pinned number, per GCP's own resource naming.
+func fetchGCPSecret(ctx context.Context, u *url.URL, cfg Config) (secretResult, error) {
+	name, err := gcpSecretName(u)
+	if err != nil {
+		return secretResult{}, err
+	}
+
+	var clientOpts []option.ClientOption
+	if cfg.AuthEnv != "" {
+		keyPath := os.Getenv(cfg.AuthEnv)
+		if keyPath == "" {
+			return secretResult{}, fmt.Errorf("GCP auth env var %q is empty or not set", cfg.AuthEnv)
+		}
+		clientOpts = append(clientOpts, option.WithCredentialsFile(keyPath))
+	}
+
+	client, err := secretmanager.NewClient(ctx, clientOpts...)
+	if err != nil {
+		return secretResult{}, fmt.Errorf("creating GCP secret manager client: %w", err)
+	}
+	defer client.Close()
+
+	resp, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	if err != nil {
+		return secretResult{}, fmt.Errorf("accessing GCP secret %s: %w", name, err)
+	}
+
+	return secretResult{Value: resp.Payload.Data}, nil
+}
+
+// k8sSecretRef splits k8s-secret://namespace/name/key into its namespace,
+// secret name, and data key.
+func k8sSecretRef(u *url.URL) (namespace, name, key string, err error) {
+	namespace = u.Host
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if namespace == "" || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("k8s-secret URL must be k8s-secret://namespace/name/key, got %q", u.String())
+	}
+	return namespace, parts[0], parts[1], nil
+}
+
+// fetchK8sSecret reads a single key out of a Kubernetes Secret using the
+// pod's own in-cluster service account; it has no use for cfg.AuthEnv.
+func fetchK8sSecret(ctx context.Context, u *url.URL, cfg Config) (secretResult, error) {
+	namespace, name, key, err := k8sSecretRef(u)
+	if err != nil {
+		return secretResult{}, err
+	}
+
+	restCfg, err := rest.InClusterConfig()
+	if err != nil {
+		return secretResult{}, fmt.Errorf("loading in-cluster kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return secretResult{}, fmt.Errorf("creating kubernetes client: %w", err)
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return secretResult{}, fmt.Errorf("getting secret %s/%s: %w", namespace, name, err)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return secretResult{}, fmt.Errorf("key %q not found in secret %s/%s", key, namespace, name)
+	}
+	return secretResult{Value: value}, nil
+}
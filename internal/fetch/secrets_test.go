@@ -0,0 +1,161 @@
+package fetch
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestVaultSecretPath(t *testing.T) {
+	u, _ := url.Parse("vault://secret/data/myapp/config")
+	path, err := vaultSecretPath(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "secret/data/myapp/config" {
+		t.Fatalf("expected %q, got %q", "secret/data/myapp/config", path)
+	}
+}
+
+func TestVaultSecretPathEmpty(t *testing.T) {
+	u, _ := url.Parse("vault://")
+	if _, err := vaultSecretPath(u); err == nil {
+		t.Fatal("expected error for empty vault path")
+	}
+}
+
+func TestAWSSecretID(t *testing.T) {
+	u, _ := url.Parse("aws-sm://prod/db/password")
+	id, err := awsSecretID(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "prod/db/password" {
+		t.Fatalf("expected %q, got %q", "prod/db/password", id)
+	}
+}
+
+func TestGCPSecretName(t *testing.T) {
+	u, _ := url.Parse("gcp-sm://projects/x/secrets/y/versions/latest")
+	name, err := gcpSecretName(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "projects/x/secrets/y/versions/latest" {
+		t.Fatalf("expected %q, got %q", "projects/x/secrets/y/versions/latest", name)
+	}
+}
+
+func TestK8sSecretRef(t *testing.T) {
+	u, _ := url.Parse("k8s-secret://default/app-config/password")
+	namespace, name, key, err := k8sSecretRef(u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if namespace != "default" || name != "app-config" || key != "password" {
+		t.Fatalf("unexpected ref: %s/%s/%s", namespace, name, key)
+	}
+}
+
+func TestK8sSecretRefMissingKey(t *testing.T) {
+	u, _ := url.Parse("k8s-secret://default/app-config")
+	if _, _, _, err := k8sSecretRef(u); err == nil {
+		t.Fatal("expected error for missing key segment")
+	}
+}
+
+func TestSelectSecretFieldFragment(t *testing.T) {
+	u, _ := url.Parse("vault://secret/data/app#password")
+	got, err := selectSecretField([]byte(`{"password":"hunter2","username":"admin"}`), u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "hunter2" {
+		t.Fatalf("expected %q, got %q", "hunter2", string(got))
+	}
+}
+
+func TestSelectSecretFieldQueryParam(t *testing.T) {
+	u, _ := url.Parse("aws-sm://prod/db/creds?field=password")
+	got, err := selectSecretField([]byte(`{"password":"hunter2"}`), u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "hunter2" {
+		t.Fatalf("expected %q, got %q", "hunter2", string(got))
+	}
+}
+
+func TestSelectSecretFieldNoSelectorPassesThrough(t *testing.T) {
+	u, _ := url.Parse("vault://secret/data/app")
+	body := []byte(`{"password":"hunter2"}`)
+	got, err := selectSecretField(body, u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != string(body) {
+		t.Fatalf("expected body unchanged, got %q", string(got))
+	}
+}
+
+func TestSelectSecretFieldMissing(t *testing.T) {
+	u, _ := url.Parse("vault://secret/data/app#missing")
+	if _, err := selectSecretField([]byte(`{"password":"hunter2"}`), u); err == nil {
+		t.Fatal("expected error for missing field")
+	}
+}
+
+func TestFormatSecretBodyRaw(t *testing.T) {
+	body := []byte(`{"a":"b"}`)
+	got, err := formatSecretBody(body, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != string(body) {
+		t.Fatalf("expected body unchanged, got %q", string(got))
+	}
+}
+
+func TestFormatSecretBodyDotenv(t *testing.T) {
+	got, err := formatSecretBody([]byte(`{"username":"admin","password":"hunter2"}`), "dotenv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "PASSWORD=hunter2\nUSERNAME=admin\n"
+	if string(got) != want {
+		t.Fatalf("expected %q, got %q", want, string(got))
+	}
+}
+
+func TestFormatSecretBodyDotenvNotObject(t *testing.T) {
+	if _, err := formatSecretBody([]byte(`"just a string"`), "dotenv"); err == nil {
+		t.Fatal("expected error formatting a non-object body as dotenv")
+	}
+}
+
+func TestFormatSecretBodyUnknown(t *testing.T) {
+	if _, err := formatSecretBody([]byte(`{}`), "xml"); err == nil {
+		t.Fatal("expected error for unknown --format")
+	}
+}
+
+func TestSecretProviderRegistryContainsAllSchemes(t *testing.T) {
+	for _, scheme := range []string{"vault", "aws-sm", "gcp-sm", "k8s-secret"} {
+		if _, ok := secretProviderRegistry[scheme]; !ok {
+			t.Fatalf("expected scheme %q to be registered", scheme)
+		}
+	}
+}
+
+func TestDoUnsupportedScheme(t *testing.T) {
+	cfg := Config{
+		URL:        "ftp://example.com/file",
+		OutputPath: "out.txt",
+		Workdir:    t.TempDir(),
+	}
+
+	_, err := Do(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("expected error for unsupported URL scheme")
+	}
+}
@@ -0,0 +1,22 @@
+package fetch
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// meter is backed by otel's global MeterProvider, a safe no-op until
+// internal/telemetry.New installs a real one.
+var meter = otel.Meter("github.com/kitstream/initium/internal/fetch")
+
+var bytesFetchedCounter, _ = meter.Int64Counter(
+	"initium_fetch_bytes_total",
+	metric.WithDescription("Total bytes successfully fetched and written to disk"),
+)
+
+// recordBytesFetched records n bytes written by a successful Do call.
+func recordBytesFetched(ctx context.Context, n int) {
+	bytesFetchedCounter.Add(ctx, int64(n))
+}
@@ -0,0 +1,211 @@
+package fetch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// TransferRequest is the single newline-delimited JSON message Fetch writes
+// to an adapter's stdin, modeled on git-lfs's custom transfer protocol.
+type TransferRequest struct {
+	Event string `json:"event"`
+	// OID identifies the object being fetched; it's set to cfg.ExpectSHA256
+	// when known, so adapters with their own content-addressable backends
+	// can verify or dedupe by digest, and left empty otherwise.
+	OID string `json:"oid,omitempty"`
+	URL string `json:"url"`
+}
+
+// TransferMessage is a single newline-delimited JSON message an adapter
+// writes to its stdout: zero or more "progress" events followed by exactly
+// one "complete" or "error" event.
+type TransferMessage struct {
+	Event string `json:"event"`
+	// Path is the local file a "complete" event leaves the fetched object
+	// at; Fetch opens it and returns it as the TransferAdapter's result.
+	Path string `json:"path,omitempty"`
+	// Error is the failure reason on an "error" event.
+	Error string `json:"error,omitempty"`
+	// BytesSoFar accompanies a "progress" event; currently logged only by
+	// adapters themselves, since TransferAdapter.Fetch has no progress
+	// callback of its own yet.
+	BytesSoFar int64 `json:"bytesSoFar,omitempty"`
+}
+
+// TransferAdapter offloads retrieval of a URL scheme initium doesn't
+// natively support (s3://, gs://, oci://, a torrent magnet link, ...) to
+// something else. Name identifies the adapter in error messages; Fetch
+// returns the object's content as a stream the caller reads fully and
+// validates exactly as it would an HTTP(S) download.
+type TransferAdapter interface {
+	Name() string
+	Fetch(ctx context.Context, req TransferRequest) (io.ReadCloser, error)
+}
+
+// Manifest maps a URL scheme to the TransferAdapter registered to handle
+// it. Do consults it before buildClient, so an adapter-backed scheme never
+// touches the HTTP client at all.
+type Manifest struct {
+	adapters map[string]TransferAdapter
+}
+
+// NewManifest returns an empty Manifest ready for Register.
+func NewManifest() *Manifest {
+	return &Manifest{adapters: make(map[string]TransferAdapter)}
+}
+
+// Register associates a TransferAdapter with a URL scheme, e.g. "s3". A
+// later Register call for the same scheme replaces the earlier one.
+func (m *Manifest) Register(scheme string, adapter TransferAdapter) {
+	m.adapters[scheme] = adapter
+}
+
+// Lookup returns the adapter registered for scheme, if any. A nil Manifest
+// (no --custom-adapter flags given) always reports not found.
+func (m *Manifest) Lookup(scheme string) (TransferAdapter, bool) {
+	if m == nil {
+		return nil, false
+	}
+	adapter, ok := m.adapters[scheme]
+	return adapter, ok
+}
+
+// ExecAdapter implements TransferAdapter by spawning Bin with Args and
+// exchanging newline-delimited JSON on its stdin/stdout: initium writes a
+// single TransferRequest line, then reads TransferMessage lines until a
+// "complete" (whose Path is opened and returned) or "error" event. This is
+// the same contract git-lfs uses for its custom transfer agents, letting
+// users bring an existing one (or a thin wrapper) rather than initium
+// needing to speak s3/gs/oci/bittorrent itself.
+type ExecAdapter struct {
+	AdapterName string
+	Bin         string
+	Args        []string
+
+	// env, when non-nil, replaces the adapter process's inherited
+	// environment; used by tests to re-exec this test binary as a fake
+	// adapter without it picking up the real one from PATH.
+	env []string
+}
+
+func (a *ExecAdapter) Name() string { return a.AdapterName }
+
+func (a *ExecAdapter) Fetch(ctx context.Context, req TransferRequest) (io.ReadCloser, error) {
+	cmd := exec.CommandContext(ctx, a.Bin, a.Args...)
+	cmd.Stderr = os.Stderr
+	if a.env != nil {
+		cmd.Env = a.env
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("adapter %s: creating stdin pipe: %w", a.AdapterName, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("adapter %s: creating stdout pipe: %w", a.AdapterName, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("adapter %s: starting %s: %w", a.AdapterName, a.Bin, err)
+	}
+
+	reqLine, err := json.Marshal(req)
+	if err != nil {
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("adapter %s: encoding request: %w", a.AdapterName, err)
+	}
+	if _, err := fmt.Fprintf(stdin, "%s\n", reqLine); err != nil {
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("adapter %s: writing request: %w", a.AdapterName, err)
+	}
+	if err := stdin.Close(); err != nil {
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("adapter %s: closing stdin: %w", a.AdapterName, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var msg TransferMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("adapter %s: decoding message %q: %w", a.AdapterName, scanner.Text(), err)
+		}
+
+		switch msg.Event {
+		case "progress":
+			continue
+		case "complete":
+			f, err := os.Open(msg.Path)
+			if err != nil {
+				_ = cmd.Wait()
+				return nil, fmt.Errorf("adapter %s: opening completed file %s: %w", a.AdapterName, msg.Path, err)
+			}
+			return &execAdapterResult{File: f, cmd: cmd}, nil
+		case "error":
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("adapter %s: %s", a.AdapterName, msg.Error)
+		default:
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("adapter %s: unknown event %q", a.AdapterName, msg.Event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("adapter %s: reading stdout: %w", a.AdapterName, err)
+	}
+
+	_ = cmd.Wait()
+	return nil, fmt.Errorf("adapter %s: exited without a complete or error event", a.AdapterName)
+}
+
+// execAdapterResult wraps the completed file so that closing it (once the
+// caller has fully read it) also reaps the adapter process, surfacing a
+// non-zero exit as an error from Close rather than leaking a zombie.
+type execAdapterResult struct {
+	*os.File
+	cmd *exec.Cmd
+}
+
+func (r *execAdapterResult) Close() error {
+	closeErr := r.File.Close()
+	waitErr := r.cmd.Wait()
+	if closeErr != nil {
+		return closeErr
+	}
+	return waitErr
+}
+
+// fetchViaAdapter streams a TransferAdapter's output into outPath's partial
+// download file (reusing the same temp-file-then-rename handling as a
+// plain HTTP(S) download) and returns the complete body for validateBody.
+func fetchViaAdapter(ctx context.Context, cfg Config, adapter TransferAdapter, outPath string) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return nil, fmt.Errorf("creating output directory: %w", err)
+	}
+
+	rc, err := adapter.Fetch(ctx, TransferRequest{Event: "download", OID: cfg.ExpectSHA256, URL: cfg.URL})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	partPath := partialPath(outPath)
+	f, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", partPath, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, rc); err != nil {
+		return nil, fmt.Errorf("adapter %s: streaming output: %w", adapter.Name(), err)
+	}
+
+	return os.ReadFile(partPath)
+}
@@ -0,0 +1,192 @@
+package fetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain lets this test binary double as the fake adapter it spawns via
+// exec.Command, the same os.Args[0]-re-exec trick the standard library
+// itself uses for testing os/exec (see os/exec/exec_test.go's
+// TestHelperProcess): INITIUM_FAKE_ADAPTER=1 short-circuits straight into
+// fakeAdapterMain instead of running the test suite.
+func TestMain(m *testing.M) {
+	if os.Getenv("INITIUM_FAKE_ADAPTER") == "1" {
+		fakeAdapterMain()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeAdapterMain implements just enough of the custom-transfer-agent
+// protocol to exercise ExecAdapter: it reads one TransferRequest line from
+// stdin, then behaves per INITIUM_FAKE_ADAPTER_MODE.
+func fakeAdapterMain() {
+	var req TransferRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "fake adapter: decoding request: %v\n", err)
+		os.Exit(1)
+	}
+
+	emit := func(msg TransferMessage) {
+		line, _ := json.Marshal(msg)
+		fmt.Println(string(line))
+	}
+
+	switch os.Getenv("INITIUM_FAKE_ADAPTER_MODE") {
+	case "error":
+		emit(TransferMessage{Event: "error", Error: "simulated adapter failure"})
+	case "badexit":
+		os.Exit(1)
+	case "garbage":
+		fmt.Println("not json")
+	default:
+		dir, err := os.MkdirTemp("", "fake-adapter")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fake adapter: %v\n", err)
+			os.Exit(1)
+		}
+		path := filepath.Join(dir, "object")
+		body := os.Getenv("INITIUM_FAKE_ADAPTER_BODY")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "fake adapter: %v\n", err)
+			os.Exit(1)
+		}
+		emit(TransferMessage{Event: "progress", BytesSoFar: int64(len(body))})
+		emit(TransferMessage{Event: "complete", Path: path})
+	}
+	os.Exit(0)
+}
+
+// fakeAdapter returns an ExecAdapter that re-execs this test binary as the
+// adapter process, with mode/body passed through the environment.
+func fakeAdapter(t *testing.T, mode, body string) *ExecAdapter {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatalf("resolving test binary path: %v", err)
+	}
+	return &ExecAdapter{
+		AdapterName: "fake",
+		Bin:         self,
+		env: append(os.Environ(),
+			"INITIUM_FAKE_ADAPTER=1",
+			"INITIUM_FAKE_ADAPTER_MODE="+mode,
+			"INITIUM_FAKE_ADAPTER_BODY="+body,
+		),
+	}
+}
+
+func TestExecAdapterFetchSuccess(t *testing.T) {
+	adapter := fakeAdapter(t, "", "hello from the fake adapter")
+
+	rc, err := adapter.Fetch(context.Background(), TransferRequest{Event: "download", URL: "fake://object"})
+	if err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+	defer rc.Close()
+
+	body := make([]byte, 256)
+	n, _ := rc.Read(body)
+	if string(body[:n]) != "hello from the fake adapter" {
+		t.Fatalf("unexpected body: %q", body[:n])
+	}
+}
+
+func TestExecAdapterFetchErrorEvent(t *testing.T) {
+	adapter := fakeAdapter(t, "error", "")
+
+	_, err := adapter.Fetch(context.Background(), TransferRequest{Event: "download", URL: "fake://object"})
+	if err == nil {
+		t.Fatal("expected error from adapter error event")
+	}
+}
+
+func TestExecAdapterFetchBadExit(t *testing.T) {
+	adapter := fakeAdapter(t, "badexit", "")
+
+	_, err := adapter.Fetch(context.Background(), TransferRequest{Event: "download", URL: "fake://object"})
+	if err == nil {
+		t.Fatal("expected error when adapter exits without a complete or error event")
+	}
+}
+
+func TestExecAdapterFetchGarbageOutput(t *testing.T) {
+	adapter := fakeAdapter(t, "garbage", "")
+
+	_, err := adapter.Fetch(context.Background(), TransferRequest{Event: "download", URL: "fake://object"})
+	if err == nil {
+		t.Fatal("expected error decoding non-JSON adapter output")
+	}
+}
+
+func TestManifestLookupUnregisteredScheme(t *testing.T) {
+	m := NewManifest()
+	if _, ok := m.Lookup("s3"); ok {
+		t.Fatal("expected no adapter registered for s3")
+	}
+}
+
+func TestManifestLookupNilManifest(t *testing.T) {
+	var m *Manifest
+	if _, ok := m.Lookup("s3"); ok {
+		t.Fatal("expected a nil Manifest to never find an adapter")
+	}
+}
+
+func TestDoFetchesViaRegisteredAdapter(t *testing.T) {
+	manifest := NewManifest()
+	manifest.Register("fake", fakeAdapter(t, "", "adapter body"))
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:        "fake://bucket/object",
+		OutputPath: "out.txt",
+		Workdir:    workdir,
+		Adapters:   manifest,
+	}
+
+	result, err := Do(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected success, got: %v", err)
+	}
+	if result.Bytes != len("adapter body") {
+		t.Fatalf("unexpected Bytes: %d", result.Bytes)
+	}
+
+	content, err := os.ReadFile(filepath.Join(workdir, "out.txt"))
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	if string(content) != "adapter body" {
+		t.Fatalf("expected %q, got %q", "adapter body", string(content))
+	}
+	if _, err := os.Stat(partialPath(filepath.Join(workdir, "out.txt"))); !os.IsNotExist(err) {
+		t.Fatalf("expected partial download file to be cleaned up, err=%v", err)
+	}
+}
+
+func TestDoAdapterExpectSHA256Mismatch(t *testing.T) {
+	manifest := NewManifest()
+	manifest.Register("fake", fakeAdapter(t, "", "adapter body"))
+
+	workdir := t.TempDir()
+	cfg := Config{
+		URL:          "fake://bucket/object",
+		OutputPath:   "out.txt",
+		Workdir:      workdir,
+		Adapters:     manifest,
+		ExpectSHA256: "deadbeef",
+	}
+
+	if _, err := Do(context.Background(), cfg); err == nil {
+		t.Fatal("expected error for --expect-sha256 mismatch")
+	}
+	if _, err := os.Stat(partialPath(filepath.Join(workdir, "out.txt"))); !os.IsNotExist(err) {
+		t.Fatalf("expected partial download file to be removed on mismatch, err=%v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package jsonpath implements a small subset of JSONPath: dotted field
+// access and bracketed array indices, enough to pull a single value out of
+// a decoded JSON response (e.g. "$.status.ready" or "items[0].name"). It is
+// not a general-purpose JSONPath implementation — no wildcards, filters, or
+// recursive descent.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Eval walks data (the result of json.Unmarshal into any) following path and
+// returns the value found there. A leading "$." or "$" is optional and
+// stripped if present.
+func Eval(data any, path string) (any, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return data, nil
+	}
+
+	cur := data
+	for _, tok := range tokenize(path) {
+		switch t := tok.(type) {
+		case string:
+			m, ok := cur.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index field %q into %T", t, cur)
+			}
+			v, ok := m[t]
+			if !ok {
+				return nil, fmt.Errorf("field %q not found", t)
+			}
+			cur = v
+		case int:
+			s, ok := cur.([]any)
+			if !ok {
+				return nil, fmt.Errorf("cannot index element [%d] into %T", t, cur)
+			}
+			if t < 0 || t >= len(s) {
+				return nil, fmt.Errorf("index [%d] out of range (len %d)", t, len(s))
+			}
+			cur = s[t]
+		}
+	}
+	return cur, nil
+}
+
+// tokenize splits "a.b[2].c" into []any{"a", "b", 2, "c"}.
+func tokenize(path string) []any {
+	var tokens []any
+	var field strings.Builder
+
+	flush := func() {
+		if field.Len() > 0 {
+			tokens = append(tokens, field.String())
+			field.Reset()
+		}
+	}
+
+	i := 0
+	for i < len(path) {
+		c := path[i]
+		switch c {
+		case '.':
+			flush()
+			i++
+		case '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				field.WriteString(path[i:])
+				i = len(path)
+				continue
+			}
+			idxStr := path[i+1 : i+end]
+			if n, err := strconv.Atoi(idxStr); err == nil {
+				tokens = append(tokens, n)
+			} else {
+				tokens = append(tokens, idxStr)
+			}
+			i += end + 1
+		default:
+			field.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,62 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("decoding test json: %v", err)
+	}
+	return v
+}
+
+func TestEvalField(t *testing.T) {
+	data := mustDecode(t, `{"status":{"ready":true}}`)
+	got, err := Eval(data, "$.status.ready")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != true {
+		t.Fatalf("expected true, got %v", got)
+	}
+}
+
+func TestEvalArrayIndex(t *testing.T) {
+	data := mustDecode(t, `{"items":[{"name":"a"},{"name":"b"}]}`)
+	got, err := Eval(data, "items[1].name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "b" {
+		t.Fatalf("expected %q, got %v", "b", got)
+	}
+}
+
+func TestEvalMissingField(t *testing.T) {
+	data := mustDecode(t, `{"status":{}}`)
+	if _, err := Eval(data, "status.ready"); err == nil {
+		t.Fatal("expected error for missing field")
+	}
+}
+
+func TestEvalIndexOutOfRange(t *testing.T) {
+	data := mustDecode(t, `{"items":[]}`)
+	if _, err := Eval(data, "items[0]"); err == nil {
+		t.Fatal("expected error for out-of-range index")
+	}
+}
+
+func TestEvalRootPath(t *testing.T) {
+	data := mustDecode(t, `"plain"`)
+	got, err := Eval(data, "$")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "plain" {
+		t.Fatalf("expected %q, got %v", "plain", got)
+	}
+}
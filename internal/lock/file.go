@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// filePollInterval is how often Acquire retries taking the lock file while
+// a concurrent holder has it.
+const filePollInterval = 200 * time.Millisecond
+
+// fileBackend implements Backend with an exclusively-created file as the
+// lock, suitable for a volume shared by every replica (e.g. an RWX PVC).
+type fileBackend struct {
+	dir string
+}
+
+func openFileBackend(rawURL string, u *url.URL) (Backend, error) {
+	dir := u.Path
+	if dir == "" {
+		return nil, fmt.Errorf("file lock backend URL %q must include a directory path, e.g. file:///var/lock", rawURL)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating lock directory %s: %w", dir, err)
+	}
+	return &fileBackend{dir: dir}, nil
+}
+
+// Acquire ignores ttl: the lock's lifetime is tied to the lock file's own
+// existence, not a lease that needs renewing.
+func (b *fileBackend) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	path := filepath.Join(b.dir, lockFileName(key))
+	ticker := time.NewTicker(filePollInterval)
+	defer ticker.Stop()
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			f.Close()
+			return &fileLease{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("creating lock file %s: %w", path, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquiring file lock %s: %w", path, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// lockFileName hashes key so migration names containing "/" or other
+// path-unsafe characters still produce a single safe filename.
+func lockFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + ".lock"
+}
+
+type fileLease struct {
+	path string
+}
+
+func (l *fileLease) Release(ctx context.Context) error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing lock file %s: %w", l.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package lock
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestFileBackend(t *testing.T) Backend {
+	t.Helper()
+	rawURL := "file://" + t.TempDir()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("unexpected error parsing test URL: %v", err)
+	}
+	backend, err := openFileBackend(rawURL, u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return backend
+}
+
+func TestFileBackendAcquireRelease(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	ctx := context.Background()
+	lease, err := backend.Acquire(ctx, "migration-a", 0)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring: %v", err)
+	}
+	if err := lease.Release(ctx); err != nil {
+		t.Fatalf("unexpected error releasing: %v", err)
+	}
+
+	// Reacquiring the same key after release should succeed immediately.
+	lease2, err := backend.Acquire(ctx, "migration-a", 0)
+	if err != nil {
+		t.Fatalf("unexpected error re-acquiring: %v", err)
+	}
+	if err := lease2.Release(ctx); err != nil {
+		t.Fatalf("unexpected error releasing: %v", err)
+	}
+}
+
+func TestFileBackendAcquireBlocksUntilReleased(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	ctx := context.Background()
+	first, err := backend.Acquire(ctx, "migration-b", 0)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring first lease: %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		lease, err := backend.Acquire(ctx, "migration-b", 0)
+		if err != nil {
+			acquired <- err
+			return
+		}
+		acquired <- lease.Release(ctx)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire returned before the first lease was released")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("unexpected error releasing first lease: %v", err)
+	}
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("second Acquire/Release failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("second Acquire did not complete after first lease was released")
+	}
+}
+
+func TestFileBackendAcquireCancelled(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	ctx := context.Background()
+	lease, err := backend.Acquire(ctx, "migration-c", 0)
+	if err != nil {
+		t.Fatalf("unexpected error acquiring first lease: %v", err)
+	}
+	defer lease.Release(ctx)
+
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, err := backend.Acquire(cancelCtx, "migration-c", 0); err == nil {
+		t.Fatal("expected error when context is cancelled before lock is free")
+	}
+}
@@ -0,0 +1,232 @@
+package lock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const k8sLeasePollInterval = 200 * time.Millisecond
+
+// k8sLeaseBackend implements Backend using a coordination.k8s.io/v1 Lease
+// as the lock, so replicas racing a migration inside the same cluster can
+// serialize without any dependency beyond the API server they already talk
+// to. It authenticates with the pod's own in-cluster service account, the
+// same as fetch's k8s-secret source.
+type k8sLeaseBackend struct {
+	clientset kubernetes.Interface
+	namespace string
+	holder    string
+}
+
+func openK8sLeaseBackend(rawURL string, u *url.URL) (Backend, error) {
+	namespace := u.Host
+	if namespace == "" {
+		return nil, fmt.Errorf("k8s-lease lock backend URL %q must include a namespace, e.g. k8s-lease://default", rawURL)
+	}
+
+	restCfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading in-cluster kubeconfig for k8s-lease backend: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating kubernetes client for k8s-lease backend: %w", err)
+	}
+
+	holder, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("resolving hostname for k8s-lease holder identity: %w", err)
+	}
+
+	return &k8sLeaseBackend{clientset: clientset, namespace: namespace, holder: holder}, nil
+}
+
+// Acquire uses ttl as the Lease's leaseDurationSeconds: once a held lease's
+// renewTime is older than that, it's considered abandoned and up for grabs.
+// ttl <= 0 uses defaultLockTTL. A background goroutine renews the lease for
+// as long as it's held, mirroring the redis backend's refresh loop.
+func (b *k8sLeaseBackend) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+	durationSeconds := int32(ttl.Seconds())
+	if durationSeconds < 1 {
+		durationSeconds = 1
+	}
+
+	name := leaseName(key)
+	ticker := time.NewTicker(k8sLeasePollInterval)
+	defer ticker.Stop()
+
+	for {
+		acquired, err := b.tryAcquire(ctx, name, durationSeconds)
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			lease := &k8sLeaseLease{
+				leases:          b.clientset.CoordinationV1().Leases(b.namespace),
+				namespace:       b.namespace,
+				name:            name,
+				holder:          b.holder,
+				durationSeconds: durationSeconds,
+				stop:            make(chan struct{}),
+			}
+			lease.wg.Add(1)
+			go lease.renewLoop(ttl)
+			return lease, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquiring k8s-lease lock %s/%s: %w", b.namespace, name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// tryAcquire creates the Lease if it doesn't exist, or takes it over if its
+// holder's renewTime has fallen outside its leaseDurationSeconds, using the
+// Lease object's resourceVersion (via Update) to fail rather than clobber a
+// concurrent takeover attempt.
+func (b *k8sLeaseBackend) tryAcquire(ctx context.Context, name string, durationSeconds int32) (bool, error) {
+	leases := b.clientset.CoordinationV1().Leases(b.namespace)
+	now := metav1.NewMicroTime(time.Now())
+
+	existing, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		holder := b.holder
+		_, createErr := leases.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: b.namespace},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(createErr) {
+			return false, nil
+		}
+		if createErr != nil {
+			return false, fmt.Errorf("creating k8s-lease %s/%s: %w", b.namespace, name, createErr)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting k8s-lease %s/%s: %w", b.namespace, name, err)
+	}
+
+	if !leaseExpired(existing) {
+		return false, nil
+	}
+
+	holder := b.holder
+	existing.Spec.HolderIdentity = &holder
+	existing.Spec.LeaseDurationSeconds = &durationSeconds
+	existing.Spec.AcquireTime = &now
+	existing.Spec.RenewTime = &now
+	if _, updateErr := leases.Update(ctx, existing, metav1.UpdateOptions{}); updateErr != nil {
+		if apierrors.IsConflict(updateErr) {
+			// Someone else took it over between our Get and our Update;
+			// retry on the next poll tick rather than treat this as fatal.
+			return false, nil
+		}
+		return false, fmt.Errorf("taking over expired k8s-lease %s/%s: %w", b.namespace, name, updateErr)
+	}
+	return true, nil
+}
+
+func leaseExpired(l *coordinationv1.Lease) bool {
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	deadline := l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(deadline)
+}
+
+// leaseName hashes key into a DNS subdomain-safe Lease object name, the
+// same approach the file backend uses for lock file names.
+func leaseName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "initium-lock-" + hex.EncodeToString(sum[:])
+}
+
+type k8sLeaseLease struct {
+	leases interface {
+		Get(ctx context.Context, name string, opts metav1.GetOptions) (*coordinationv1.Lease, error)
+		Update(ctx context.Context, lease *coordinationv1.Lease, opts metav1.UpdateOptions) (*coordinationv1.Lease, error)
+		Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	}
+	namespace       string
+	name            string
+	holder          string
+	durationSeconds int32
+	stop            chan struct{}
+	wg              sync.WaitGroup
+}
+
+func (l *k8sLeaseLease) renewLoop(ttl time.Duration) {
+	defer l.wg.Done()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			// Best-effort: if this fails, or if we've lost the lease to a
+			// takeover (see isCurrentHolder), it simply expires on its own
+			// rather than us extending a lease we no longer hold.
+			existing, err := l.leases.Get(context.Background(), l.name, metav1.GetOptions{})
+			if err != nil || !l.isCurrentHolder(existing) {
+				continue
+			}
+			now := metav1.NewMicroTime(time.Now())
+			existing.Spec.RenewTime = &now
+			l.leases.Update(context.Background(), existing, metav1.UpdateOptions{})
+		}
+	}
+}
+
+// isCurrentHolder reports whether this lease still owns lease, guarding
+// against a stale holder (past its ttl and taken over by someone else)
+// renewing or deleting a Lease object it no longer actually holds.
+func (l *k8sLeaseLease) isCurrentHolder(lease *coordinationv1.Lease) bool {
+	return lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == l.holder
+}
+
+func (l *k8sLeaseLease) Release(ctx context.Context) error {
+	close(l.stop)
+	l.wg.Wait()
+
+	existing, err := l.leases.Get(ctx, l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading k8s-lease %s/%s before release: %w", l.namespace, l.name, err)
+	}
+	if !l.isCurrentHolder(existing) {
+		// Our lease expired and someone else already took it over; deleting
+		// now would drop their lock out from under them.
+		return nil
+	}
+
+	if err := l.leases.Delete(ctx, l.name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("releasing k8s-lease %s/%s: %w", l.namespace, l.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package lock
+
+import "testing"
+
+func TestOpenUnsupportedScheme(t *testing.T) {
+	if _, err := Open("memcached://localhost"); err == nil {
+		t.Fatal("expected error for unsupported lock backend scheme")
+	}
+}
+
+func TestOpenInvalidURL(t *testing.T) {
+	if _, err := Open("://not-a-url"); err == nil {
+		t.Fatal("expected error for invalid lock backend URL")
+	}
+}
+
+func TestOpenFileScheme(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := Open("file://" + dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := backend.(*fileBackend); !ok {
+		t.Fatalf("expected *fileBackend, got %T", backend)
+	}
+}
+
+func TestOpenK8sLeaseSchemeRequiresNamespace(t *testing.T) {
+	if _, err := Open("k8s-lease://"); err == nil {
+		t.Fatal("expected error for k8s-lease URL missing a namespace")
+	}
+}
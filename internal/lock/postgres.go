@@ -0,0 +1,68 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresBackend implements Backend using PostgreSQL session-level
+// advisory locks (pg_advisory_lock/pg_advisory_unlock), keyed by a 64-bit
+// hash of the caller's key since pg_advisory_lock takes a bigint. Advisory
+// locks are scoped to the session that took them, so the same *sql.Conn
+// must be held for the lease's whole lifetime rather than returned to a
+// pool between Acquire and Release.
+type postgresBackend struct {
+	db *sql.DB
+}
+
+func openPostgresBackend(rawURL string, u *url.URL) (Backend, error) {
+	db, err := sql.Open("postgres", rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("opening postgres lock backend: %w", err)
+	}
+	return &postgresBackend{db: db}, nil
+}
+
+// Acquire ignores ttl: a postgres advisory lock's lifetime is tied to the
+// session (conn) that took it, released explicitly or when that session
+// closes, not a server-enforced expiry.
+func (b *postgresBackend) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	conn, err := b.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("opening postgres connection for advisory lock: %w", err)
+	}
+
+	lockKey := advisoryLockKey(key)
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("acquiring postgres advisory lock %d: %w", lockKey, err)
+	}
+	return &postgresLease{conn: conn, key: lockKey}, nil
+}
+
+// advisoryLockKey hashes key into the signed 64-bit space pg_advisory_lock
+// expects, via FNV-1a truncated to int64.
+func advisoryLockKey(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+type postgresLease struct {
+	conn *sql.Conn
+	key  int64
+}
+
+func (l *postgresLease) Release(ctx context.Context) error {
+	defer l.conn.Close()
+	if _, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+		return fmt.Errorf("releasing postgres advisory lock %d: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisPollInterval = 200 * time.Millisecond
+
+// redisBackend implements Backend using SET NX PX (atomic set-if-absent
+// with a TTL), fenced by a random per-lease token so a lease can only be
+// released by the holder that set it, not by a later holder that grabbed
+// the key after this one's TTL expired. A background goroutine refreshes
+// the TTL for as long as the lease is held, so a live holder running a
+// slow migration isn't evicted out from under itself.
+type redisBackend struct {
+	client *redis.Client
+}
+
+func openRedisBackend(rawURL string, u *url.URL) (Backend, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis lock backend URL: %w", err)
+	}
+	return &redisBackend{client: redis.NewClient(opts)}, nil
+}
+
+// Acquire uses ttl as the key's expiry (PX); ttl <= 0 uses defaultLockTTL.
+// A background goroutine refreshes that expiry for as long as the lease is
+// held, so a live holder running a slow migration isn't evicted out from
+// under itself.
+func (b *redisBackend) Acquire(ctx context.Context, key string, ttl time.Duration) (Lease, error) {
+	if ttl <= 0 {
+		ttl = defaultLockTTL
+	}
+
+	token, err := fencingToken()
+	if err != nil {
+		return nil, fmt.Errorf("generating fencing token: %w", err)
+	}
+
+	ticker := time.NewTicker(redisPollInterval)
+	defer ticker.Stop()
+
+	for {
+		ok, err := b.client.SetNX(ctx, key, token, ttl).Result()
+		if err != nil {
+			return nil, fmt.Errorf("acquiring redis lock %q: %w", key, err)
+		}
+		if ok {
+			lease := &redisLease{client: b.client, key: key, token: token, ttl: ttl, stop: make(chan struct{})}
+			lease.wg.Add(1)
+			go lease.refreshLoop()
+			return lease, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("acquiring redis lock %q: %w", key, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func fencingToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+type redisLease struct {
+	client *redis.Client
+	key    string
+	token  string
+	ttl    time.Duration
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func (l *redisLease) refreshLoop() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			// Best-effort: if this fails the lock simply expires on its
+			// own TTL, which just means a concurrent runner might start
+			// early rather than corrupting any state.
+			l.client.Expire(context.Background(), l.key, l.ttl)
+		}
+	}
+}
+
+// releaseScript deletes key only if its value still matches the fencing
+// token this lease set, so a lease that outlived its TTL can't delete a
+// lock someone else has since acquired.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+func (l *redisLease) Release(ctx context.Context) error {
+	close(l.stop)
+	l.wg.Wait()
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil && err != redis.Nil {
+		return fmt.Errorf("releasing redis lock %q: %w", l.key, err)
+	}
+	return nil
+}
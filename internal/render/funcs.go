@@ -0,0 +1,284 @@
+package render
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kitstream/initium/internal/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// FuncMap returns the curated set of template helpers available to
+// render's gotemplate mode. It is a small, Sprig-inspired subset rather
+// than Sprig itself, so the surface stays easy to audit. Filesystem
+// helpers beyond readFile (expandenv, getHostByName, and friends) are
+// left out on purpose: env and .Env already expose the whole process
+// environment to every template, so excluding them would add no
+// protection, but readFile reaching outside --workdir could exfiltrate
+// data it has no business seeing, which is why it's added by
+// newTemplate (sandboxed to workdir via safety.ValidateFilePath) rather
+// than living here unconditionally.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"upper":           strings.ToUpper,
+		"lower":           strings.ToLower,
+		"trim":            strings.TrimSpace,
+		"indent":          indent,
+		"nindent":         nindent,
+		"quote":           quote,
+		"squote":          squote,
+		"default":         defaultVal,
+		"required":        required,
+		"toYaml":          toYaml,
+		"fromYaml":        fromYaml,
+		"toJson":          toJSON,
+		"fromJson":        fromJSON,
+		"b64enc":          b64enc,
+		"b64dec":          b64dec,
+		"sha256sum":       sha256sum,
+		"trimSuffix":      trimSuffix,
+		"trimPrefix":      trimPrefix,
+		"replace":         replaceAll,
+		"regexReplaceAll": regexReplaceAll,
+		"semverCompare":   semver.Satisfies,
+		"date":            dateFormat,
+		"env":             os.Getenv,
+		"hasKey":          hasKey,
+		"list":            list,
+		"dict":            dict,
+		"get":             get,
+		"split":           split,
+		"join":            join,
+		"randAlphaNum":    randAlphaNum,
+	}
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = pad + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func quote(v any) string {
+	return fmt.Sprintf("%q", fmt.Sprint(v))
+}
+
+func squote(v any) string {
+	return "'" + strings.ReplaceAll(fmt.Sprint(v), "'", `\'`) + "'"
+}
+
+// nindent is indent with a leading newline, the common helm idiom for
+// dropping a block under a YAML key without fiddling with the key's own
+// indentation: {{ toYaml .Values | nindent 2 }}.
+func nindent(spaces int, s string) string {
+	return "\n" + indent(spaces, s)
+}
+
+func defaultVal(def, val any) any {
+	if isEmpty(val) {
+		return def
+	}
+	return val
+}
+
+func required(msg string, val any) (any, error) {
+	if isEmpty(val) {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return val, nil
+}
+
+func isEmpty(val any) bool {
+	switch v := val.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	default:
+		return false
+	}
+}
+
+func toYaml(v any) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshalling to yaml: %w", err)
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func fromYaml(s string) (any, error) {
+	var v any
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("unmarshalling yaml: %w", err)
+	}
+	return v, nil
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshalling to json: %w", err)
+	}
+	return string(data), nil
+}
+
+func fromJSON(s string) (any, error) {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("unmarshalling json: %w", err)
+	}
+	return v, nil
+}
+
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func b64dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("decoding base64: %w", err)
+	}
+	return string(data), nil
+}
+
+func sha256sum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return fmt.Sprintf("%x", sum)
+}
+
+// trimSuffix and trimPrefix take their affix first, matching Sprig's
+// argument order so {{ .Name | trimSuffix ".yaml" }} reads naturally.
+func trimSuffix(suffix, s string) string {
+	return strings.TrimSuffix(s, suffix)
+}
+
+func trimPrefix(prefix, s string) string {
+	return strings.TrimPrefix(s, prefix)
+}
+
+func replaceAll(old, new, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
+
+func regexReplaceAll(pattern, s, repl string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("compiling regex %q: %w", pattern, err)
+	}
+	return re.ReplaceAllString(s, repl), nil
+}
+
+// dateFormat formats t (a time.Time, a unix timestamp, or an RFC3339
+// string) using layout, which follows the same reference-time syntax as
+// the rest of the standard library.
+func dateFormat(layout string, t any) (string, error) {
+	parsed, err := asTime(t)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Format(layout), nil
+}
+
+// hasKey reports whether m contains key, so templates can branch on
+// optional .Values entries without triggering a missingkey error:
+// {{ if hasKey .Values "replicas" }}...{{ end }}.
+func hasKey(m map[string]any, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// list builds a slice from its arguments, the counterpart to dict for
+// passing ad-hoc collections into include or range.
+func list(items ...any) []any {
+	return items
+}
+
+// dict builds a map[string]any from alternating key/value arguments, the
+// Sprig idiom for assembling ad-hoc data to pass to include:
+// {{ include "labels" (dict "name" .Values.name) }}.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict requires an even number of arguments, got %d", len(pairs))
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v (%T) must be a string", pairs[i], pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// get looks up key in m, returning nil rather than an error when it is
+// absent so it composes with default: {{ get .Values "replicas" | default 1 }}.
+func get(m map[string]any, key string) any {
+	return m[key]
+}
+
+// split breaks s into a slice on sep, Sprig's argument order
+// (separator first) so {{ split "," .CSV }} reads naturally.
+func split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// join is split's counterpart, Sprig's argument order (separator first,
+// then the slice) so {{ join "," .List }} reads naturally.
+func join(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+const randAlphaNumAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// randAlphaNum returns a random alphanumeric string of length n, e.g. for
+// a throwaway {{ randAlphaNum 8 }} suffix on a generated resource name.
+// It is not meant for anything security-sensitive: use a secret-provider
+// fetch for actual credentials.
+func randAlphaNum(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("randAlphaNum: length must be >= 0, got %d", n)
+	}
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, big.NewInt(int64(len(randAlphaNumAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("randAlphaNum: generating random index: %w", err)
+		}
+		out[i] = randAlphaNumAlphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
+
+func asTime(v any) (time.Time, error) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case int64:
+		return time.Unix(t, 0).UTC(), nil
+	case int:
+		return time.Unix(int64(t), 0).UTC(), nil
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing time %q: %w", t, err)
+		}
+		return parsed, nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time value %v (%T)", v, v)
+	}
+}
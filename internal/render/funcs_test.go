@@ -0,0 +1,172 @@
+package render
+
+import "testing"
+
+func TestFuncMapEnv(t *testing.T) {
+	t.Setenv("RENDER_FUNC_ENV", "envval")
+
+	got, err := GoTemplateWithData(`{{env "RENDER_FUNC_ENV"}}`, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "envval" {
+		t.Fatalf("expected %q, got %q", "envval", got)
+	}
+}
+
+func TestFuncMapDictOddArgsErrors(t *testing.T) {
+	_, err := GoTemplateWithData(`{{dict "a"}}`, map[string]any{}, false)
+	if err == nil {
+		t.Fatal("expected error for dict with an odd number of arguments")
+	}
+}
+
+func TestFuncMapInclude(t *testing.T) {
+	input := `{{define "greeting"}}hello {{.}}{{end}}{{include "greeting" "world"}}`
+	got, err := GoTemplateWithData(input, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hello world" {
+		t.Fatalf("expected %q, got %q", "hello world", got)
+	}
+}
+
+func TestFuncMapIncludeComposesWithIndent(t *testing.T) {
+	input := `{{define "block"}}a
+b{{end}}{{include "block" . | indent 2}}`
+	got, err := GoTemplateWithData(input, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "  a\n  b" {
+		t.Fatalf("expected %q, got %q", "  a\n  b", got)
+	}
+}
+
+func TestFuncMapGoTemplateHelpers(t *testing.T) {
+	tests := []struct {
+		name, tmpl, want string
+	}{
+		{"upper", `{{upper "abc"}}`, "ABC"},
+		{"lower", `{{lower "ABC"}}`, "abc"},
+		{"trim", `{{trim "  x  "}}`, "x"},
+		{"default used", `{{default "fallback" ""}}`, "fallback"},
+		{"default unused", `{{default "fallback" "value"}}`, "value"},
+		{"quote", `{{quote "a b"}}`, `"a b"`},
+		{"indent", "{{indent 2 \"a\\nb\"}}", "  a\n  b"},
+		{"b64enc", `{{b64enc "hi"}}`, "aGk="},
+		{"b64dec", `{{b64dec "aGk="}}`, "hi"},
+		{"sha256sum", `{{sha256sum "abc"}}`, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"regexReplaceAll", `{{regexReplaceAll "[0-9]+" "port8080" "NUM"}}`, "portNUM"},
+		{"semverCompare", `{{semverCompare ">=1.2.0" "1.3.0"}}`, "true"},
+		{"toJson", `{{toJson "x"}}`, `"x"`},
+		{"squote", `{{squote "a b"}}`, "'a b'"},
+		{"nindent", "{{nindent 2 \"a\\nb\"}}", "\n  a\n  b"},
+		{"trimSuffix", `{{trimSuffix ".yaml" "app.yaml"}}`, "app"},
+		{"trimPrefix", `{{trimPrefix "app." "app.yaml"}}`, "yaml"},
+		{"replace", `{{replace "-" "_" "my-app"}}`, "my_app"},
+		{"fromJson", `{{(fromJson "{\"a\":1}").a}}`, "1"},
+		{"hasKey true", `{{hasKey .Values "a"}}`, "true"},
+		{"hasKey false", `{{hasKey .Values "b"}}`, "false"},
+		{"list", `{{range list "a" "b"}}{{.}}{{end}}`, "ab"},
+		{"get present", `{{get .Values "a"}}`, "1"},
+		{"get missing", `{{get .Values "b" | default "fallback"}}`, "fallback"},
+		{"dict", `{{(dict "x" "y").x}}`, "y"},
+		{"fromYaml", `{{(fromYaml "a: 1\n").a}}`, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := map[string]any{"Values": map[string]any{"a": 1}}
+			got, err := GoTemplateWithData(tt.tmpl, data, false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFuncMapRequiredMissing(t *testing.T) {
+	_, err := GoTemplateWithData(`{{required "name is required" .Name}}`, map[string]any{}, false)
+	if err == nil {
+		t.Fatal("expected error for missing required value")
+	}
+}
+
+func TestFuncMapRequiredPresent(t *testing.T) {
+	got, err := GoTemplateWithData(`{{required "name is required" .Name}}`, map[string]any{"Name": "app"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "app" {
+		t.Fatalf("expected %q, got %q", "app", got)
+	}
+}
+
+func TestFuncMapToYaml(t *testing.T) {
+	got, err := GoTemplateWithData(`{{toYaml .Items}}`, map[string]any{"Items": []string{"a", "b"}}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "- a\n- b"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFuncMapDateFormat(t *testing.T) {
+	got, err := GoTemplateWithData(`{{date "2006-01-02" .When}}`, map[string]any{"When": "2026-07-27T00:00:00Z"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "2026-07-27" {
+		t.Fatalf("expected %q, got %q", "2026-07-27", got)
+	}
+}
+
+func TestGoTemplateWithDataStrictMissingKey(t *testing.T) {
+	_, err := GoTemplateWithData(`{{.Missing}}`, map[string]any{}, true)
+	if err == nil {
+		t.Fatal("expected error for missing key in strict mode")
+	}
+}
+
+func TestGoTemplateWithDataNonStrictMissingKey(t *testing.T) {
+	got, err := GoTemplateWithData(`val={{.Missing}}`, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "val=<no value>" {
+		t.Fatalf("expected %q, got %q", "val=<no value>", got)
+	}
+}
+
+func TestFuncMapSplitJoin(t *testing.T) {
+	got, err := GoTemplateWithData(`{{join "-" (split "," "a,b,c")}}`, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "a-b-c" {
+		t.Fatalf("expected %q, got %q", "a-b-c", got)
+	}
+}
+
+func TestFuncMapRandAlphaNumLength(t *testing.T) {
+	got, err := GoTemplateWithData(`{{randAlphaNum 12}}`, map[string]any{}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 12 {
+		t.Fatalf("expected length 12, got %d (%q)", len(got), got)
+	}
+}
+
+func TestRandAlphaNumNegativeLengthErrors(t *testing.T) {
+	if _, err := randAlphaNum(-1); err == nil {
+		t.Fatal("expected error for negative length")
+	}
+}
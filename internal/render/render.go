@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"text/template"
+
+	"github.com/kitstream/initium/internal/safety"
 )
 
 var envsubstPattern = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}|\$([a-zA-Z_][a-zA-Z0-9_]*)`)
@@ -28,8 +31,11 @@ func Envsubst(input string) string {
 func GoTemplate(input string) (string, error) {
 	envMap := envToMap()
 
-	tmpl, err := template.New("initium").Option("missingkey=zero").Parse(input)
+	tmpl, err := newTemplate("initium", "missingkey=zero", "", "")
 	if err != nil {
+		return "", err
+	}
+	if tmpl, err = tmpl.Parse(input); err != nil {
 		return "", fmt.Errorf("parsing template: %w", err)
 	}
 
@@ -41,6 +47,136 @@ func GoTemplate(input string) (string, error) {
 	return buf.String(), nil
 }
 
+// TemplateData is the structured data context gotemplate mode builds once
+// it has more than a flat env map to expose: Env holds the process
+// environment (moved out of the top level so it doesn't collide with
+// Values keys), Files holds the contents of any --set-file entries keyed
+// by their name, and Values holds whatever --values/--set assembled. A
+// template still sees a flat env-var map at the top level instead of this
+// struct when none of --values, --set, --set-file is given and it doesn't
+// reference .Env, for backward compatibility with existing templates (see
+// cmd's render.go for the exact heuristic).
+type TemplateData struct {
+	Env    map[string]string
+	Files  map[string]string
+	Values map[string]any
+}
+
+// GoTemplateWithData renders input against an arbitrary data value
+// (typically a flat map[string]string of env vars, or a TemplateData once
+// --values/--set/--set-file are in play) using the same Sprig-inspired
+// function map as GoTemplate. In strict mode, referencing a key that is
+// absent from data is a render error instead of printing "<no value>",
+// mirroring Helm's --strict/required behavior.
+func GoTemplateWithData(input string, data any, strict bool) (string, error) {
+	return GoTemplateWithDataDir(input, data, strict, "")
+}
+
+// GoTemplateWithDataDir is GoTemplateWithData plus support for a directory
+// of partials (e.g. _helpers.tpl files) loaded alongside input, so that
+// {{ template "header" . }} and {{ include "header" . }} resolve against
+// names defined with {{ define }} in templateDir. An empty templateDir
+// behaves exactly like GoTemplateWithData.
+func GoTemplateWithDataDir(input string, data any, strict bool, templateDir string) (string, error) {
+	return GoTemplateWithDataDirWorkdir(input, data, strict, templateDir, "")
+}
+
+// GoTemplateWithDataDirWorkdir is GoTemplateWithDataDir plus a workdir used
+// to sandbox the readFile helper (see newTemplate): readFile resolves its
+// argument relative to workdir and rejects any path escaping it, the same
+// way --output does. An empty workdir leaves readFile unavailable, since
+// there's nothing to sandbox it to.
+func GoTemplateWithDataDirWorkdir(input string, data any, strict bool, templateDir, workdir string) (string, error) {
+	option := "missingkey=invalid"
+	if strict {
+		option = "missingkey=error"
+	}
+
+	tmpl, err := newTemplate("initium", option, templateDir, workdir)
+	if err != nil {
+		return "", err
+	}
+	if tmpl, err = tmpl.Parse(input); err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// newTemplate builds an *template.Template with FuncMap() plus include and
+// readFile, which both need more context than a stateless FuncMap entry
+// can close over and so are added via a second Funcs call: include needs a
+// reference to the template it belongs to (to resolve the name), the Helm
+// idiom for rendering a named {{ define }} block into a string so it
+// composes with indent/nindent; readFile needs workdir to sandbox its
+// argument the same way --output is sandboxed, so a template can't read
+// anything outside the directory the render command was already trusted
+// with. When templateDir is non-empty, every file in it is parsed into
+// tmpl first so its {{ define }} blocks are available to the caller's
+// input and to each other.
+func newTemplate(name, option, templateDir, workdir string) (*template.Template, error) {
+	tmpl := template.New(name).Option(option).Funcs(FuncMap())
+	tmpl.Funcs(template.FuncMap{
+		"include": func(name string, data any) (string, error) {
+			var buf bytes.Buffer
+			if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		},
+		"readFile": func(path string) (string, error) {
+			if workdir == "" {
+				return "", fmt.Errorf("readFile %q: not available without a --workdir to sandbox it to", path)
+			}
+			resolved, err := safety.ValidateFilePath(workdir, path)
+			if err != nil {
+				return "", fmt.Errorf("readFile %q: %w", path, err)
+			}
+			data, err := os.ReadFile(resolved)
+			if err != nil {
+				return "", fmt.Errorf("readFile %q: %w", path, err)
+			}
+			return string(data), nil
+		},
+	})
+
+	if templateDir != "" {
+		matches, err := filepath.Glob(filepath.Join(templateDir, "*"))
+		if err != nil {
+			return nil, fmt.Errorf("globbing template dir %s: %w", templateDir, err)
+		}
+		if len(matches) > 0 {
+			if tmpl, err = tmpl.ParseFiles(matches...); err != nil {
+				return nil, fmt.Errorf("parsing template dir %s: %w", templateDir, err)
+			}
+		}
+	}
+
+	return tmpl, nil
+}
+
+// EnvData returns the process environment as a map[string]any suitable for
+// merging with values loaded from LoadValuesFile and passing to
+// GoTemplateWithData.
+func EnvData() map[string]any {
+	data := make(map[string]any)
+	for k, v := range envToMap() {
+		data[k] = v
+	}
+	return data
+}
+
+// EnvMap returns the process environment as a map[string]string, for
+// building a TemplateData.Env value.
+func EnvMap() map[string]string {
+	return envToMap()
+}
+
 func envToMap() map[string]string {
 	m := make(map[string]string)
 	for _, entry := range os.Environ() {
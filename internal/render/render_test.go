@@ -2,6 +2,7 @@ package render
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -166,6 +167,31 @@ func TestGoTemplateSpecialChars(t *testing.T) {
 	}
 }
 
+func TestGoTemplateWithDataDirLoadsPartials(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "_helpers.tpl"), []byte(`{{define "header"}}# {{.Name}}{{end}}`), 0o644); err != nil {
+		t.Fatalf("writing partial: %v", err)
+	}
+
+	got, err := GoTemplateWithDataDir(`{{template "header" .}}`, map[string]any{"Name": "app"}, false, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "# app" {
+		t.Fatalf("expected %q, got %q", "# app", got)
+	}
+}
+
+func TestGoTemplateWithDataDirEmptyBehavesLikeWithoutDir(t *testing.T) {
+	got, err := GoTemplateWithDataDir(`val={{.Name}}`, map[string]any{"Name": "app"}, false, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "val=app" {
+		t.Fatalf("expected %q, got %q", "val=app", got)
+	}
+}
+
 func TestEnvToMap(t *testing.T) {
 	t.Setenv("RENDER_TEST_MAP", "mapval")
 
@@ -182,3 +208,58 @@ func TestEnvsubstDollarWithoutVar(t *testing.T) {
 		t.Fatalf("expected %q, got %q", input, got)
 	}
 }
+
+func TestGoTemplateWithDataDirWorkdirReadFile(t *testing.T) {
+	workdir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workdir, "greeting.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatalf("writing fixture file: %v", err)
+	}
+
+	got, err := GoTemplateWithDataDirWorkdir(`{{readFile "greeting.txt"}}`, map[string]any{}, false, "", workdir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hello" {
+		t.Fatalf("expected %q, got %q", "hello", got)
+	}
+}
+
+func TestGoTemplateWithDataDirWorkdirReadFileWithoutWorkdirErrors(t *testing.T) {
+	_, err := GoTemplateWithDataDirWorkdir(`{{readFile "greeting.txt"}}`, map[string]any{}, false, "", "")
+	if err == nil {
+		t.Fatal("expected error when readFile is used without a workdir")
+	}
+}
+
+func TestGoTemplateWithDataDirWorkdirReadFileRejectsPathEscape(t *testing.T) {
+	workdir := t.TempDir()
+	_, err := GoTemplateWithDataDirWorkdir(`{{readFile "../secret.txt"}}`, map[string]any{}, false, "", workdir)
+	if err == nil {
+		t.Fatal("expected error for a readFile path escaping workdir")
+	}
+}
+
+func TestTemplateDataStructAsRenderContext(t *testing.T) {
+	data := TemplateData{
+		Env:    map[string]string{"HOST": "localhost"},
+		Files:  map[string]string{"ca": "cert-bytes"},
+		Values: map[string]any{"replicas": 3},
+	}
+
+	got, err := GoTemplateWithData(`{{.Env.HOST}} {{.Files.ca}} {{.Values.replicas}}`, data, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "localhost cert-bytes 3" {
+		t.Fatalf("expected %q, got %q", "localhost cert-bytes 3", got)
+	}
+}
+
+func TestEnvMap(t *testing.T) {
+	t.Setenv("RENDER_ENV_MAP", "envmapval")
+
+	m := EnvMap()
+	if m["RENDER_ENV_MAP"] != "envmapval" {
+		t.Fatalf("expected envmapval, got %q", m["RENDER_ENV_MAP"])
+	}
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadValuesFile reads a YAML or JSON file (chosen by its extension, YAML
+// by default) into a map suitable for use as gotemplate data.
+func LoadValuesFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file %s: %w", path, err)
+	}
+
+	values := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("parsing values file %s as json: %w", path, err)
+		}
+		return values, nil
+	}
+
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing values file %s as yaml: %w", path, err)
+	}
+	return values, nil
+}
+
+// MergeValues deep-merges src into dst in place: a nested map in src is
+// merged key by key into the corresponding map in dst, and any other
+// value (including a slice) overwrites dst's. Callers use it to layer
+// repeated --values files on top of one another in the order given.
+func MergeValues(dst, src map[string]any) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]any); ok {
+			if dstMap, ok := dst[k].(map[string]any); ok {
+				MergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// SetValue applies a single --set key=value override to dst, following
+// Helm's dotted-path convention: --set a.b.c=x creates or overwrites
+// dst["a"]["b"]["c"]. Values are always stored as strings; callers
+// needing richer types should use --values instead.
+func SetValue(dst map[string]any, expr string) error {
+	key, value, ok := strings.Cut(expr, "=")
+	if !ok {
+		return fmt.Errorf("--set %q must be in key=value form", expr)
+	}
+
+	parts := strings.Split(key, ".")
+	m := dst
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+	return nil
+}
+
+// RedactMap returns a deep copy of data with any map value whose key
+// case-insensitively matches one of keys replaced by "***". It is used to
+// scrub render context before it is included in log output, so a template
+// execution error cannot leak secret values to stdout.
+func RedactMap(data map[string]any, keys []string) map[string]any {
+	redact := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		redact[strings.ToLower(k)] = true
+	}
+	return redactMap(data, redact)
+}
+
+func redactMap(data map[string]any, redact map[string]bool) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		if redact[strings.ToLower(k)] {
+			out[k] = "***"
+			continue
+		}
+		out[k] = redactValue(v, redact)
+	}
+	return out
+}
+
+func redactValue(v any, redact map[string]bool) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return redactMap(val, redact)
+	case map[string]string:
+		return redactStringMap(val, redact)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = redactValue(item, redact)
+		}
+		return out
+	default:
+		return val
+	}
+}
+
+// redactStringMap is redactMap's counterpart for map[string]string values
+// such as TemplateData.Env and TemplateData.Files, whose leaves are never
+// themselves maps or slices but can still match a --redact-key, e.g. a
+// --set-file name holding certificate or token contents.
+func redactStringMap(data map[string]string, redact map[string]bool) map[string]any {
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		if redact[strings.ToLower(k)] {
+			out[k] = "***"
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadValuesFileYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(path, []byte("service:\n  name: app\n  replicas: 3\n"), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	values, err := LoadValuesFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service, ok := values["service"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected service to be a map, got %T", values["service"])
+	}
+	if service["name"] != "app" {
+		t.Fatalf("expected name=app, got %v", service["name"])
+	}
+}
+
+func TestLoadValuesFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.json")
+	if err := os.WriteFile(path, []byte(`{"service":{"name":"app"}}`), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	values, err := LoadValuesFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	service, ok := values["service"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected service to be a map, got %T", values["service"])
+	}
+	if service["name"] != "app" {
+		t.Fatalf("expected name=app, got %v", service["name"])
+	}
+}
+
+func TestLoadValuesFileMissing(t *testing.T) {
+	if _, err := LoadValuesFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatal("expected error for missing values file")
+	}
+}
+
+func TestMergeValuesOverlaysNested(t *testing.T) {
+	dst := map[string]any{"service": map[string]any{"name": "app", "replicas": 1}}
+	src := map[string]any{"service": map[string]any{"replicas": 3}}
+
+	MergeValues(dst, src)
+
+	service := dst["service"].(map[string]any)
+	if service["name"] != "app" {
+		t.Fatalf("expected unrelated key to survive the merge, got %v", service["name"])
+	}
+	if service["replicas"] != 3 {
+		t.Fatalf("expected replicas overlaid to 3, got %v", service["replicas"])
+	}
+}
+
+func TestMergeValuesNonMapOverwrites(t *testing.T) {
+	dst := map[string]any{"tags": []any{"a"}}
+	src := map[string]any{"tags": []any{"b", "c"}}
+
+	MergeValues(dst, src)
+
+	if !reflect.DeepEqual(dst["tags"], []any{"b", "c"}) {
+		t.Fatalf("expected tags replaced wholesale, got %v", dst["tags"])
+	}
+}
+
+func TestSetValueNestedPath(t *testing.T) {
+	dst := map[string]any{}
+	if err := SetValue(dst, "service.replicas=3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service, ok := dst["service"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected service to be a map, got %T", dst["service"])
+	}
+	if service["replicas"] != "3" {
+		t.Fatalf("expected replicas=3, got %v", service["replicas"])
+	}
+}
+
+func TestSetValueRequiresEquals(t *testing.T) {
+	if err := SetValue(map[string]any{}, "no-equals-sign"); err == nil {
+		t.Fatal("expected error for --set without key=value form")
+	}
+}
+
+func TestRedactMapTopLevel(t *testing.T) {
+	got := RedactMap(map[string]any{"password": "hunter2", "user": "alice"}, []string{"password"})
+	want := map[string]any{"password": "***", "user": "alice"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRedactMapNested(t *testing.T) {
+	got := RedactMap(map[string]any{
+		"db": map[string]any{"password": "hunter2", "host": "localhost"},
+	}, []string{"password"})
+	want := map[string]any{
+		"db": map[string]any{"password": "***", "host": "localhost"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRedactMapCaseInsensitive(t *testing.T) {
+	got := RedactMap(map[string]any{"API_KEY": "secret"}, []string{"api_key"})
+	if got["API_KEY"] != "***" {
+		t.Fatalf("expected API_KEY to be redacted, got %v", got["API_KEY"])
+	}
+}
+
+func TestRedactMapNestedStringMap(t *testing.T) {
+	got := RedactMap(map[string]any{
+		"Files": map[string]string{"tls-ca": "-----BEGIN CERT-----", "name": "myapp"},
+	}, []string{"tls-ca"})
+	want := map[string]any{
+		"Files": map[string]any{"tls-ca": "***", "name": "myapp"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Breaker.Allow (and therefore by Do) while the
+// breaker is open and its cooldown has not yet elapsed.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Breaker is a consecutive-failure circuit breaker shared across one or more
+// retry.Do loops, e.g. several wait-for targets hitting the same backend.
+// After FailureThreshold consecutive failures it opens and short-circuits
+// every call with ErrCircuitOpen until Cooldown has elapsed, then allows a
+// single half-open probe through; a successful probe closes the breaker, a
+// failed one reopens it for another Cooldown.
+type Breaker struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	mu               sync.Mutex
+	consecutiveFails int
+	openedAt         time.Time
+	probeInFlight    bool
+}
+
+// NewBreaker returns a Breaker that opens after failureThreshold consecutive
+// failures and stays open for cooldown before allowing a half-open probe.
+func NewBreaker(failureThreshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		FailureThreshold: failureThreshold,
+		Cooldown:         cooldown,
+	}
+}
+
+// Allow reports whether a call may proceed. It returns ErrCircuitOpen if the
+// breaker is open and either the cooldown hasn't elapsed or a half-open
+// probe is already in flight.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.consecutiveFails < b.FailureThreshold {
+		return nil
+	}
+
+	if time.Since(b.openedAt) < b.Cooldown {
+		return ErrCircuitOpen
+	}
+
+	if b.probeInFlight {
+		return ErrCircuitOpen
+	}
+
+	b.probeInFlight = true
+	return nil
+}
+
+func (b *Breaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails = 0
+	b.probeInFlight = false
+}
+
+func (b *Breaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails++
+	b.probeInFlight = false
+	if b.consecutiveFails >= b.FailureThreshold {
+		b.openedAt = time.Now()
+	}
+}
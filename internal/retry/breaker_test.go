@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerAllowsUntilThreshold(t *testing.T) {
+	b := NewBreaker(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("attempt %d: expected allow, got %v", i, err)
+		}
+		b.recordFailure()
+	}
+
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after threshold, got %v", err)
+	}
+}
+
+func TestBreakerHalfOpenAfterCooldown(t *testing.T) {
+	b := NewBreaker(1, 10*time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected allow, got %v", err)
+	}
+	b.recordFailure()
+
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open immediately after tripping, got %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected half-open probe to be allowed after cooldown, got %v", err)
+	}
+	b.recordSuccess()
+
+	if err := b.Allow(); err != nil {
+		t.Fatalf("expected closed breaker to allow, got %v", err)
+	}
+}
+
+func TestDoWithOpenBreakerShortCircuits(t *testing.T) {
+	breaker := NewBreaker(1, time.Minute)
+	breaker.recordFailure() // trip it before Do runs
+
+	cfg := Config{
+		MaxAttempts:   5,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		BackoffFactor: 1.0,
+		Breaker:       breaker,
+	}
+
+	calls := 0
+	result := Do(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		return nil
+	})
+
+	if !errors.Is(result.Err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", result.Err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected fn not to be called while breaker is open, got %d calls", calls)
+	}
+}
@@ -2,18 +2,62 @@ package retry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand/v2"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrBudgetExhausted is returned by Do/DoWithHooks when sleeping before the
+// next attempt would exceed Config.TotalBudget. The last attempt's error is
+// wrapped so callers can still inspect what was failing.
+var ErrBudgetExhausted = errors.New("retry budget exhausted")
+
+// JitterMode selects how Delay randomizes the backoff between attempts.
+type JitterMode string
+
+const (
+	// JitterModeAdditive is the default: delay + delay*JitterFraction*rand(0,1).
+	JitterModeAdditive JitterMode = ""
+	// JitterModeFull is AWS's "full jitter": rand(0, cappedExponentialDelay).
+	JitterModeFull JitterMode = "full"
+	// JitterModeDecorrelated computes the next delay from the previous one:
+	// min(MaxDelay, rand(InitialDelay, prevDelay*3)).
+	JitterModeDecorrelated JitterMode = "decorrelated"
 )
 
 type Config struct {
+	// Name identifies the calling command (e.g. "fetch", "wait-for") for the
+	// "cmd" label on retry spans and metrics. Left empty, attempts are still
+	// traced and counted, just under an empty label.
+	Name string
+
 	MaxAttempts    int
 	InitialDelay   time.Duration
 	MaxDelay       time.Duration
 	BackoffFactor  float64
-	JitterFraction float64 // 0.0–1.0: fraction of delay to add as random jitter
+	JitterFraction float64 // 0.0–1.0: fraction of delay to add as random jitter (JitterModeAdditive only)
+
+	// JitterMode selects the backoff strategy. Defaults to JitterModeAdditive.
+	JitterMode JitterMode
+
+	// Breaker, when set, is consulted before every attempt and updated with
+	// the outcome of every attempt. See Breaker for details.
+	Breaker *Breaker
+
+	// TotalBudget, when nonzero, caps the wall-clock time spent across all
+	// attempts and sleeps combined. Do checks the budget before each sleep
+	// rather than after, so it never sleeps past the deadline only to
+	// discover it afterwards.
+	TotalBudget time.Duration
+	// PerAttemptTimeout, when nonzero, bounds a single fn invocation with
+	// context.WithTimeout, independent of TotalBudget.
+	PerAttemptTimeout time.Duration
 }
 
 func DefaultConfig() Config {
@@ -42,46 +86,194 @@ func (c Config) Validate() error {
 	if c.JitterFraction < 0 || c.JitterFraction > 1 {
 		return fmt.Errorf("jitter-fraction must be in [0, 1], got %f", c.JitterFraction)
 	}
+	switch c.JitterMode {
+	case JitterModeAdditive, JitterModeFull, JitterModeDecorrelated:
+	default:
+		return fmt.Errorf("jitter-mode must be one of \"\", %q, %q, got %q", JitterModeFull, JitterModeDecorrelated, c.JitterMode)
+	}
+	if c.TotalBudget < 0 {
+		return fmt.Errorf("total-budget must be >= 0, got %s", c.TotalBudget)
+	}
+	if c.PerAttemptTimeout < 0 {
+		return fmt.Errorf("per-attempt-timeout must be >= 0, got %s", c.PerAttemptTimeout)
+	}
 	return nil
 }
 
-func Delay(cfg Config, attempt int) time.Duration {
+// cappedExponentialDelay returns InitialDelay*BackoffFactor^attempt, capped
+// at MaxDelay, with no jitter applied.
+func cappedExponentialDelay(cfg Config, attempt int) float64 {
 	delay := float64(cfg.InitialDelay) * math.Pow(cfg.BackoffFactor, float64(attempt))
 	if delay > float64(cfg.MaxDelay) {
 		delay = float64(cfg.MaxDelay)
 	}
+	return delay
+}
+
+// Delay computes the backoff before the next attempt under JitterModeAdditive
+// or JitterModeFull. JitterModeDecorrelated needs the previous delay and is
+// computed separately by DecorrelatedDelay.
+func Delay(cfg Config, attempt int) time.Duration {
+	delay := cappedExponentialDelay(cfg, attempt)
+
+	switch cfg.JitterMode {
+	case JitterModeFull:
+		return time.Duration(rand.Float64() * delay)
+	default:
+		if cfg.JitterFraction > 0 {
+			delay += delay * cfg.JitterFraction * rand.Float64()
+		}
+		return time.Duration(delay)
+	}
+}
 
-	if cfg.JitterFraction > 0 {
-		jitter := delay * cfg.JitterFraction * rand.Float64()
-		delay += jitter
+// DecorrelatedDelay implements the "decorrelated jitter" backoff: the next
+// delay is drawn uniformly from [InitialDelay, prevDelay*3], capped at
+// MaxDelay. Pass a zero prevDelay for the first attempt.
+func DecorrelatedDelay(cfg Config, prevDelay time.Duration) time.Duration {
+	lo := float64(cfg.InitialDelay)
+	if prevDelay <= 0 {
+		prevDelay = cfg.InitialDelay
+	}
+	hi := float64(prevDelay) * 3
+	if hi < lo {
+		hi = lo
 	}
 
+	delay := lo + rand.Float64()*(hi-lo)
+	if delay > float64(cfg.MaxDelay) {
+		delay = float64(cfg.MaxDelay)
+	}
 	return time.Duration(delay)
 }
 
 type Result struct {
 	Attempt int
 	Err     error
+
+	// Elapsed is the total wall-clock time spent in Do, including sleeps.
+	Elapsed time.Duration
+	// Sleeps records the duration of each backoff sleep actually taken, in
+	// order. Its length is one less than the number of attempts made,
+	// since there is no sleep after the final attempt.
+	Sleeps []time.Duration
 }
 
+// OnRetryFunc is called by DoWithHooks after an attempt fails but before its
+// backoff sleep, with the attempt index, its error, and the delay about to
+// be slept. It is the integration point for structured retry logging via
+// internal/logging.
+type OnRetryFunc func(attempt int, err error, nextDelay time.Duration)
+
 func Do(ctx context.Context, cfg Config, fn func(ctx context.Context, attempt int) error) Result {
+	return DoWithHooks(ctx, cfg, fn, nil)
+}
+
+// DoWithHooks behaves like Do but invokes onRetry (if non-nil) before each
+// backoff sleep.
+func DoWithHooks(ctx context.Context, cfg Config, fn func(ctx context.Context, attempt int) error, onRetry OnRetryFunc) Result {
+	start := time.Now()
+	var prevDelay time.Duration
+	var sleeps []time.Duration
+
 	for attempt := range cfg.MaxAttempts {
-		err := fn(ctx, attempt)
+		if cfg.Breaker != nil {
+			if err := cfg.Breaker.Allow(); err != nil {
+				return Result{Attempt: attempt, Err: err, Elapsed: time.Since(start), Sleeps: sleeps}
+			}
+		}
+
+		attemptCtx := ctx
+		cancel := func() {}
+		if cfg.PerAttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, cfg.PerAttemptTimeout)
+		}
+
+		spanCtx, span := tracer.Start(attemptCtx, "retry.attempt", trace.WithAttributes(
+			attribute.Int("attempt", attempt+1),
+			attribute.String("cmd", cfg.Name),
+		))
+
+		err := fn(spanCtx, attempt)
+		cancel()
+
+		if cfg.Breaker != nil {
+			if err == nil {
+				cfg.Breaker.recordSuccess()
+			} else {
+				cfg.Breaker.recordFailure()
+			}
+		}
+
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+			span.SetAttributes(attribute.String("error.type", fmt.Sprintf("%T", err)))
+		}
+
+		// endAttempt closes the span and records the attempt metric with the
+		// backoff (if any) that follows it; call it exactly once per attempt,
+		// right before returning or moving on to the sleep.
+		endAttempt := func(delay time.Duration) {
+			span.SetAttributes(attribute.String("outcome", outcome), attribute.Int64("delay_ms", delay.Milliseconds()))
+			span.End()
+			attemptsCounter.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("cmd", cfg.Name),
+				attribute.String("outcome", outcome),
+			))
+		}
+
 		if err == nil {
-			return Result{Attempt: attempt, Err: nil}
+			endAttempt(0)
+			return Result{Attempt: attempt, Err: nil, Elapsed: time.Since(start), Sleeps: sleeps}
 		}
 
 		if attempt == cfg.MaxAttempts-1 {
-			return Result{Attempt: attempt, Err: fmt.Errorf("all %d attempts failed, last error: %w", cfg.MaxAttempts, err)}
+			endAttempt(0)
+			return Result{
+				Attempt: attempt,
+				Err:     fmt.Errorf("all %d attempts failed, last error: %w", cfg.MaxAttempts, err),
+				Elapsed: time.Since(start),
+				Sleeps:  sleeps,
+			}
+		}
+
+		var delay time.Duration
+		if cfg.JitterMode == JitterModeDecorrelated {
+			delay = DecorrelatedDelay(cfg, prevDelay)
+		} else {
+			delay = Delay(cfg, attempt)
+		}
+		prevDelay = delay
+
+		endAttempt(delay)
+		backoffHistogram.Record(ctx, delay.Seconds(), metric.WithAttributes(attribute.String("cmd", cfg.Name)))
+
+		if cfg.TotalBudget > 0 && time.Since(start)+delay > cfg.TotalBudget {
+			return Result{
+				Attempt: attempt,
+				Err:     fmt.Errorf("%w after attempt %d: last error: %w", ErrBudgetExhausted, attempt+1, err),
+				Elapsed: time.Since(start),
+				Sleeps:  sleeps,
+			}
+		}
+
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
 		}
 
-		delay := Delay(cfg, attempt)
 		select {
 		case <-ctx.Done():
-			return Result{Attempt: attempt, Err: fmt.Errorf("context cancelled after attempt %d: %w", attempt+1, ctx.Err())}
+			return Result{
+				Attempt: attempt,
+				Err:     fmt.Errorf("context cancelled after attempt %d: %w", attempt+1, ctx.Err()),
+				Elapsed: time.Since(start),
+				Sleeps:  sleeps,
+			}
 		case <-time.After(delay):
+			sleeps = append(sleeps, delay)
 		}
 	}
 
-	return Result{Err: fmt.Errorf("max attempts reached")}
+	return Result{Err: fmt.Errorf("max attempts reached"), Elapsed: time.Since(start), Sleeps: sleeps}
 }
@@ -174,6 +174,222 @@ func TestDoAllFail(t *testing.T) {
 	}
 }
 
+func TestDelayFullJitterWithinBounds(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   10,
+		InitialDelay:  100 * time.Millisecond,
+		MaxDelay:      10 * time.Second,
+		BackoffFactor: 2.0,
+		JitterMode:    JitterModeFull,
+	}
+
+	for i := 0; i < 20; i++ {
+		d := Delay(cfg, 3)
+		if d < 0 || d > 800*time.Millisecond {
+			t.Fatalf("full jitter delay %s out of bounds [0, 800ms]", d)
+		}
+	}
+}
+
+func TestDecorrelatedDelayWithinBounds(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   10,
+		InitialDelay:  100 * time.Millisecond,
+		MaxDelay:      2 * time.Second,
+		BackoffFactor: 2.0,
+	}
+
+	prev := time.Duration(0)
+	for i := 0; i < 20; i++ {
+		d := DecorrelatedDelay(cfg, prev)
+		if d < cfg.InitialDelay || d > cfg.MaxDelay {
+			t.Fatalf("decorrelated delay %s out of bounds [%s, %s]", d, cfg.InitialDelay, cfg.MaxDelay)
+		}
+		prev = d
+	}
+}
+
+func TestConfigValidationJitterMode(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.JitterMode = "bogus"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for invalid jitter mode")
+	}
+}
+
+func TestDoDecorrelatedJitterSucceeds(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   5,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		BackoffFactor: 1.0,
+		JitterMode:    JitterModeDecorrelated,
+	}
+
+	calls := 0
+	result := Do(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+
+	if result.Err != nil {
+		t.Fatalf("expected success, got: %v", result.Err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoRecordsElapsedAndSleeps(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   3,
+		InitialDelay:  5 * time.Millisecond,
+		MaxDelay:      5 * time.Millisecond,
+		BackoffFactor: 1.0,
+	}
+
+	calls := 0
+	result := Do(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+
+	if result.Err != nil {
+		t.Fatalf("expected success, got: %v", result.Err)
+	}
+	if len(result.Sleeps) != 2 {
+		t.Fatalf("expected 2 recorded sleeps, got %d", len(result.Sleeps))
+	}
+	if result.Elapsed <= 0 {
+		t.Fatal("expected nonzero elapsed time")
+	}
+}
+
+func TestDoBudgetExhaustedBeforeSleep(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   10,
+		InitialDelay:  50 * time.Millisecond,
+		MaxDelay:      50 * time.Millisecond,
+		BackoffFactor: 1.0,
+		TotalBudget:   10 * time.Millisecond,
+	}
+
+	calls := 0
+	result := Do(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		return errors.New("fail")
+	})
+
+	if !errors.Is(result.Err, ErrBudgetExhausted) {
+		t.Fatalf("expected ErrBudgetExhausted, got %v", result.Err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call before budget check stops further sleeps, got %d", calls)
+	}
+}
+
+func TestDoPerAttemptTimeout(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   1,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      time.Millisecond,
+		BackoffFactor: 1.0,
+	}
+	cfg.PerAttemptTimeout = 5 * time.Millisecond
+
+	result := Do(context.Background(), cfg, func(ctx context.Context, _ int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	if result.Err == nil {
+		t.Fatal("expected error from per-attempt timeout")
+	}
+}
+
+func TestDoWithHooksCallsOnRetry(t *testing.T) {
+	cfg := Config{
+		MaxAttempts:   3,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		BackoffFactor: 1.0,
+	}
+
+	var retried []int
+	calls := 0
+	result := DoWithHooks(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	}, func(attempt int, err error, nextDelay time.Duration) {
+		retried = append(retried, attempt)
+		if err == nil {
+			t.Fatal("expected non-nil error passed to onRetry")
+		}
+		if nextDelay <= 0 {
+			t.Fatal("expected positive next delay passed to onRetry")
+		}
+	})
+
+	if result.Err != nil {
+		t.Fatalf("expected success, got: %v", result.Err)
+	}
+	if len(retried) != 2 {
+		t.Fatalf("expected onRetry called twice, got %d", len(retried))
+	}
+}
+
+func TestConfigValidationBudgetFields(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.TotalBudget = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for negative total budget")
+	}
+
+	cfg = DefaultConfig()
+	cfg.PerAttemptTimeout = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for negative per-attempt timeout")
+	}
+}
+
+func TestDoRecordsNamedSpansAndMetricsWithoutProvider(t *testing.T) {
+	// No telemetry provider is installed in tests, so tracer.Start and the
+	// package's instruments are no-ops; this only exercises that setting
+	// Config.Name doesn't change retry behavior.
+	cfg := Config{
+		Name:          "fetch",
+		MaxAttempts:   3,
+		InitialDelay:  time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		BackoffFactor: 1.0,
+	}
+
+	calls := 0
+	result := Do(context.Background(), cfg, func(_ context.Context, _ int) error {
+		calls++
+		if calls < 2 {
+			return errors.New("not ready")
+		}
+		return nil
+	})
+
+	if result.Err != nil {
+		t.Fatalf("expected success, got: %v", result.Err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
 func TestDoContextCancelled(t *testing.T) {
 	cfg := Config{
 		MaxAttempts:    100,
@@ -0,0 +1,26 @@
+package retry
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// tracer and meter are backed by otel's global providers, which are safe
+// no-ops until internal/telemetry.New installs real ones. This package has
+// no direct dependency on internal/telemetry so that low-level retry logic
+// never needs a telemetry handle threaded through its callers.
+var (
+	tracer = otel.Tracer("github.com/kitstream/initium/internal/retry")
+	meter  = otel.Meter("github.com/kitstream/initium/internal/retry")
+)
+
+var (
+	attemptsCounter, _ = meter.Int64Counter(
+		"initium_retry_attempts_total",
+		metric.WithDescription("Number of retry attempts made, labeled by command and outcome"),
+	)
+	backoffHistogram, _ = meter.Float64Histogram(
+		"initium_retry_backoff_seconds",
+		metric.WithDescription("Backoff delay slept between retry attempts, in seconds"),
+	)
+)
@@ -0,0 +1,119 @@
+// Package semver implements the minimal subset of semantic version parsing
+// and comparison that the render template functions need. It intentionally
+// does not depend on an external semver library.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+func Parse(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core := s
+	var pre string
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		core = s[:i]
+		pre = s[i+1:]
+	}
+
+	parts := strings.SplitN(core, ".", 3)
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid semver %q: expected MAJOR.MINOR.PATCH", s)
+	}
+
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid semver %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Pre: pre}, nil
+}
+
+// Compare returns -1, 0, or 1 if a is less than, equal to, or greater than b.
+// A version with a pre-release tag is considered lower than the same
+// version without one.
+func Compare(a, b Version) int {
+	if a.Major != b.Major {
+		return cmpInt(a.Major, b.Major)
+	}
+	if a.Minor != b.Minor {
+		return cmpInt(a.Minor, b.Minor)
+	}
+	if a.Patch != b.Patch {
+		return cmpInt(a.Patch, b.Patch)
+	}
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	default:
+		return strings.Compare(a.Pre, b.Pre)
+	}
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Satisfies evaluates a constraint of the form "<op><version>", where op is
+// one of =, ==, !=, >, >=, <, <=. An omitted op is treated as equality.
+func Satisfies(constraint, version string) (bool, error) {
+	op, rest := splitOp(strings.TrimSpace(constraint))
+
+	want, err := Parse(rest)
+	if err != nil {
+		return false, fmt.Errorf("parsing constraint %q: %w", constraint, err)
+	}
+	got, err := Parse(version)
+	if err != nil {
+		return false, fmt.Errorf("parsing version %q: %w", version, err)
+	}
+
+	cmp := Compare(got, want)
+	switch op {
+	case "=", "==", "":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported constraint operator %q", op)
+	}
+}
+
+func splitOp(s string) (op, rest string) {
+	for _, candidate := range []string{">=", "<=", "==", "!=", ">", "<", "="} {
+		if strings.HasPrefix(s, candidate) {
+			return candidate, strings.TrimSpace(strings.TrimPrefix(s, candidate))
+		}
+	}
+	return "", s
+}
@@ -0,0 +1,72 @@
+package semver
+
+import "testing"
+
+func TestParseValid(t *testing.T) {
+	v, err := Parse("v1.2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Major != 1 || v.Minor != 2 || v.Patch != 3 {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse("1.2"); err == nil {
+		t.Fatal("expected error for incomplete version")
+	}
+	if _, err := Parse("a.b.c"); err == nil {
+		t.Fatal("expected error for non-numeric version")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"2.0.0", "1.9.9", 1},
+		{"1.2.0-rc1", "1.2.0", -1},
+	}
+
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Fatalf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		want                bool
+	}{
+		{">=1.2.0", "1.3.0", true},
+		{">=1.2.0", "1.1.0", false},
+		{"<2.0.0", "1.9.9", true},
+		{"==1.0.0", "1.0.0", true},
+		{"!=1.0.0", "1.0.1", true},
+		{"1.0.0", "1.0.0", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Satisfies(tt.constraint, tt.version)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Fatalf("Satisfies(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestSatisfiesInvalidConstraint(t *testing.T) {
+	if _, err := Satisfies("~>1.0.0", "1.0.0"); err == nil {
+		t.Fatal("expected error for unsupported operator")
+	}
+}
@@ -0,0 +1,13 @@
+package telemetry
+
+import (
+	"net/http"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// promHandler renders registry in the Prometheus text exposition format.
+func promHandler(registry *promclient.Registry) http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,153 @@
+// Package telemetry wires initium's subcommands into OpenTelemetry tracing
+// and Prometheus-compatible metrics. It is intentionally thin: it only
+// configures the global otel TracerProvider/MeterProvider and a metrics
+// exporter (listen or push). Instrumentation itself (spans, counters) lives
+// next to the code it measures — internal/retry, internal/fetch, and
+// internal/cmd — and reaches this package only through otel's global
+// providers (otel.Tracer/otel.Meter), never through a direct dependency on
+// this package.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Config controls whether and how tracing and metrics are exported. The zero
+// value disables tracing (no OTELEndpoint) and leaves metrics uncollectable
+// from the outside (no MetricsListen, no PushGatewayURL) — every recorded
+// span and instrument still exists, but nothing reads them.
+type Config struct {
+	// OTELEndpoint is the OTLP collector endpoint (host:port). Empty disables
+	// trace export entirely; otel.Tracer calls remain safe no-ops.
+	OTELEndpoint string
+	// OTELProtocol selects the OTLP wire protocol: "grpc" (default) or "http".
+	OTELProtocol string
+
+	// MetricsListen, when set, serves Prometheus-format metrics on this
+	// address (e.g. ":9090") for the lifetime of the command.
+	MetricsListen string
+	// PushGatewayURL, when set, pushes metrics once to a Prometheus
+	// PushGateway during Shutdown instead of serving them. Init containers
+	// typically exit before anything could ever scrape a listener, so this
+	// is the recommended mode for short-lived runs. Takes precedence over
+	// MetricsListen.
+	PushGatewayURL string
+	// PushGatewayJob names the "job" grouping key used for pushed metrics.
+	// Defaults to "initium" when empty.
+	PushGatewayJob string
+}
+
+// Telemetry holds the process-wide tracing and metrics handles created by
+// New. Callers keep it around only to call Shutdown before exit.
+type Telemetry struct {
+	cfg Config
+
+	tracerProvider *sdktrace.TracerProvider
+	registry       *promclient.Registry
+	server         *http.Server
+}
+
+// New configures the global otel tracer and meter providers according to
+// cfg and starts the metrics listener or prepares the push gateway client.
+// It never returns an error for an empty Config — telemetry is always
+// optional, and commands must run identically whether or not it is enabled.
+func New(cfg Config) (*Telemetry, error) {
+	t := &Telemetry{cfg: cfg, registry: promclient.NewRegistry()}
+
+	if cfg.OTELEndpoint != "" {
+		tp, err := newTracerProvider(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("configuring tracer provider: %w", err)
+		}
+		t.tracerProvider = tp
+		otel.SetTracerProvider(tp)
+	}
+
+	exporter, err := prometheus.New(prometheus.WithRegisterer(t.registry))
+	if err != nil {
+		return nil, fmt.Errorf("configuring metrics exporter: %w", err)
+	}
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	otel.SetMeterProvider(mp)
+
+	if cfg.MetricsListen != "" && cfg.PushGatewayURL == "" {
+		t.server = startListener(cfg.MetricsListen, t.registry)
+	}
+
+	return t, nil
+}
+
+// Shutdown flushes any pending spans, pushes metrics to the PushGateway if
+// configured, and stops the metrics listener. It is safe to call on a
+// Telemetry returned from a Config with everything disabled.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if t.cfg.PushGatewayURL != "" {
+		job := t.cfg.PushGatewayJob
+		if job == "" {
+			job = "initium"
+		}
+		if err := push.New(t.cfg.PushGatewayURL, job).Gatherer(t.registry).Push(); err != nil {
+			return fmt.Errorf("pushing metrics to %s: %w", t.cfg.PushGatewayURL, err)
+		}
+	}
+
+	if t.server != nil {
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := t.server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("stopping metrics listener: %w", err)
+		}
+	}
+
+	if t.tracerProvider != nil {
+		if err := t.tracerProvider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("shutting down tracer provider: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newTracerProvider(cfg Config) (*sdktrace.TracerProvider, error) {
+	ctx := context.Background()
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.OTELProtocol {
+	case "", "grpc":
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(cfg.OTELEndpoint), otlptracegrpc.WithInsecure())
+	case "http":
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(cfg.OTELEndpoint), otlptracehttp.WithInsecure())
+	default:
+		return nil, fmt.Errorf("--otel-protocol must be grpc or http, got %q", cfg.OTELProtocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)), nil
+}
+
+func startListener(addr string, registry *promclient.Registry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promHandler(registry))
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		_ = srv.ListenAndServe()
+	}()
+	return srv
+}
@@ -0,0 +1,58 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewDisabledIsNoOp(t *testing.T) {
+	tel, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+}
+
+func TestNewInvalidProtocol(t *testing.T) {
+	_, err := New(Config{OTELEndpoint: "127.0.0.1:4317", OTELProtocol: "carrier-pigeon"})
+	if err == nil {
+		t.Fatal("expected error for unsupported OTEL protocol")
+	}
+}
+
+func TestNewMetricsListenerServesMetrics(t *testing.T) {
+	tel, err := New(Config{MetricsListen: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer tel.Shutdown(context.Background())
+
+	// The listener address is randomized by the OS via ":0"; exercise the
+	// handler directly rather than guessing the bound port.
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	promHandler(tel.registry).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 from /metrics, got %d", rec.Code)
+	}
+}
+
+func TestShutdownWithoutListenerOrPushGateway(t *testing.T) {
+	tel, err := New(Config{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tel.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package user synthesizes /etc/passwd and /etc/group entries so that
+// containers running under an arbitrary, unregistered UID (common under
+// OpenShift and PSP/PSA-restricted Kubernetes) still resolve via getpwuid(3)
+// for tools like psql, git, and ssh that refuse to run otherwise.
+package user
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kitstream/initium/internal/safety"
+)
+
+type Config struct {
+	UID      int
+	GID      int
+	Username string
+	Home     string
+	Shell    string
+	Gecos    string
+
+	// PasswdTemplate and GroupTemplate, when set, are copied verbatim and
+	// appended to rather than replaced; both are typically read-only source
+	// files (e.g. the image's own /etc/passwd) and are not subject to
+	// safety.ValidateFilePath since they are read, never written.
+	PasswdTemplate string
+	GroupTemplate  string
+
+	// Workdir, PasswdOutput, and GroupOutput locate the output files the
+	// same way every other subcommand does: PasswdOutput/GroupOutput are
+	// relative paths validated against Workdir via safety.ValidateFilePath.
+	Workdir      string
+	PasswdOutput string
+	GroupOutput  string
+
+	// Nsswitch additionally writes a minimal /etc/nsswitch.conf to
+	// NsswitchOutput (relative to Workdir) that prefers "files" so the
+	// synthesized entries above are actually consulted.
+	Nsswitch       bool
+	NsswitchOutput string
+
+	// StdoutOnly skips all file writes; Do only returns the formatted lines,
+	// for initContainers that hand the lines to a shared emptyDir themselves.
+	StdoutOnly bool
+}
+
+// DefaultConfig returns a Config seeded with the invoking process's own
+// UID/GID, matching the common case of "make this UID resolvable".
+func DefaultConfig() Config {
+	return Config{
+		UID:            os.Getuid(),
+		GID:            os.Getgid(),
+		Username:       "initium",
+		Home:           "/",
+		Shell:          "/sbin/nologin",
+		PasswdOutput:   "passwd",
+		GroupOutput:    "group",
+		NsswitchOutput: "nsswitch.conf",
+	}
+}
+
+func (c Config) Validate() error {
+	if c.Username == "" {
+		return fmt.Errorf("username must not be empty")
+	}
+	if c.UID < 0 {
+		return fmt.Errorf("uid must be >= 0, got %d", c.UID)
+	}
+	if c.GID < 0 {
+		return fmt.Errorf("gid must be >= 0, got %d", c.GID)
+	}
+	if c.Home == "" {
+		return fmt.Errorf("home must not be empty")
+	}
+	if c.Shell == "" {
+		return fmt.Errorf("shell must not be empty")
+	}
+	if !c.StdoutOnly && c.Workdir == "" {
+		return fmt.Errorf("workdir must not be empty")
+	}
+	return nil
+}
+
+// PasswdLine formats cfg as a single /etc/passwd entry:
+// name:x:uid:gid:gecos:home:shell.
+func PasswdLine(cfg Config) string {
+	return fmt.Sprintf("%s:x:%d:%d:%s:%s:%s", cfg.Username, cfg.UID, cfg.GID, cfg.Gecos, cfg.Home, cfg.Shell)
+}
+
+// GroupLine formats cfg as a single /etc/group entry: name:x:gid:members.
+// initium never synthesizes supplementary members, so the members field is
+// always empty.
+func GroupLine(cfg Config) string {
+	return fmt.Sprintf("%s:x:%d:", cfg.Username, cfg.GID)
+}
+
+// Result reports what Do produced. Paths are empty when StdoutOnly is set.
+type Result struct {
+	PasswdLine   string
+	GroupLine    string
+	PasswdPath   string
+	GroupPath    string
+	NsswitchPath string
+}
+
+const nsswitchConf = `passwd: files
+group:  files
+shadow: files
+hosts:  files dns
+`
+
+// Do synthesizes the passwd and group entries for cfg and, unless
+// cfg.StdoutOnly, writes them atomically to Workdir/PasswdOutput and
+// Workdir/GroupOutput (copy-then-append over PasswdTemplate/GroupTemplate
+// when set). Re-running Do with the same cfg is idempotent: if an entry for
+// cfg.UID (passwd) or cfg.GID (group) already exists in the template/output,
+// it is left untouched rather than duplicated.
+func Do(cfg Config) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		PasswdLine: PasswdLine(cfg),
+		GroupLine:  GroupLine(cfg),
+	}
+
+	if cfg.StdoutOnly {
+		return result, nil
+	}
+
+	passwdPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.PasswdOutput)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid passwd output path: %w", err)
+	}
+	groupPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.GroupOutput)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid group output path: %w", err)
+	}
+
+	passwdContent, err := mergeEntry(cfg.PasswdTemplate, result.PasswdLine, 2, strconv.Itoa(cfg.UID))
+	if err != nil {
+		return Result{}, fmt.Errorf("merging passwd template: %w", err)
+	}
+	groupContent, err := mergeEntry(cfg.GroupTemplate, result.GroupLine, 2, strconv.Itoa(cfg.GID))
+	if err != nil {
+		return Result{}, fmt.Errorf("merging group template: %w", err)
+	}
+
+	if err := safety.WriteFileAtomic(passwdPath, []byte(passwdContent), 0o644); err != nil {
+		return Result{}, fmt.Errorf("writing %s: %w", passwdPath, err)
+	}
+	if err := safety.WriteFileAtomic(groupPath, []byte(groupContent), 0o644); err != nil {
+		return Result{}, fmt.Errorf("writing %s: %w", groupPath, err)
+	}
+	result.PasswdPath = passwdPath
+	result.GroupPath = groupPath
+
+	if cfg.Nsswitch {
+		nsswitchPath, err := safety.ValidateFilePath(cfg.Workdir, cfg.NsswitchOutput)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid nsswitch output path: %w", err)
+		}
+		if err := safety.WriteFileAtomic(nsswitchPath, []byte(nsswitchConf), 0o644); err != nil {
+			return Result{}, fmt.Errorf("writing %s: %w", nsswitchPath, err)
+		}
+		result.NsswitchPath = nsswitchPath
+	}
+
+	return result, nil
+}
+
+// mergeEntry reads templatePath (if set), and, unless a colon-delimited
+// record already has key at fieldIdx, appends newLine to its content. It
+// never mutates templatePath itself — callers write the returned content to
+// a separate output path.
+func mergeEntry(templatePath, newLine string, fieldIdx int, key string) (string, error) {
+	var existing []byte
+	if templatePath != "" {
+		b, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("reading template %s: %w", templatePath, err)
+		}
+		existing = b
+	}
+
+	if hasField(existing, fieldIdx, key) {
+		return string(existing), nil
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += newLine + "\n"
+	return content, nil
+}
+
+// hasField reports whether any colon-delimited line in content has key at
+// fieldIdx (0-based), e.g. fieldIdx 2 is the uid field of a passwd line.
+func hasField(content []byte, fieldIdx int, key string) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) > fieldIdx && fields[fieldIdx] == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPasswdLineFormat(t *testing.T) {
+	cfg := Config{Username: "app", UID: 1001, GID: 0, Gecos: "App User", Home: "/home/app", Shell: "/bin/bash"}
+	got := PasswdLine(cfg)
+	want := "app:x:1001:0:App User:/home/app:/bin/bash"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestGroupLineFormat(t *testing.T) {
+	cfg := Config{Username: "app", GID: 1001}
+	got := GroupLine(cfg)
+	want := "app:x:1001:"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestConfigValidationRequiresUsername(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Username = ""
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for empty username")
+	}
+}
+
+func TestConfigValidationRejectsNegativeUID(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.UID = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for negative uid")
+	}
+}
+
+func TestDoWritesPasswdAndGroup(t *testing.T) {
+	workdir := t.TempDir()
+	cfg := Config{
+		Username:     "app",
+		UID:          1001,
+		GID:          1001,
+		Home:         "/home/app",
+		Shell:        "/bin/sh",
+		Workdir:      workdir,
+		PasswdOutput: "passwd",
+		GroupOutput:  "group",
+	}
+
+	result, err := Do(cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	passwd, err := os.ReadFile(filepath.Join(workdir, "passwd"))
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+	if string(passwd) != result.PasswdLine+"\n" {
+		t.Fatalf("unexpected passwd content: %q", string(passwd))
+	}
+
+	group, err := os.ReadFile(filepath.Join(workdir, "group"))
+	if err != nil {
+		t.Fatalf("reading group output: %v", err)
+	}
+	if string(group) != result.GroupLine+"\n" {
+		t.Fatalf("unexpected group content: %q", string(group))
+	}
+}
+
+func TestDoStdoutOnlyWritesNoFiles(t *testing.T) {
+	workdir := t.TempDir()
+	cfg := Config{
+		Username:   "app",
+		UID:        1001,
+		GID:        1001,
+		Home:       "/home/app",
+		Shell:      "/bin/sh",
+		Workdir:    workdir,
+		StdoutOnly: true,
+	}
+
+	result, err := Do(cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if result.PasswdPath != "" || result.GroupPath != "" {
+		t.Fatalf("expected no paths written in stdout-only mode, got %+v", result)
+	}
+
+	entries, err := os.ReadDir(workdir)
+	if err != nil {
+		t.Fatalf("reading workdir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected no files written, found %d", len(entries))
+	}
+}
+
+func TestDoAppendsToTemplatePreservingExistingEntries(t *testing.T) {
+	workdir := t.TempDir()
+	template := filepath.Join(workdir, "passwd.tmpl")
+	if err := os.WriteFile(template, []byte("root:x:0:0:root:/root:/bin/bash\n"), 0o644); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+
+	cfg := Config{
+		Username:       "app",
+		UID:            1001,
+		GID:            1001,
+		Home:           "/home/app",
+		Shell:          "/bin/sh",
+		Workdir:        workdir,
+		PasswdOutput:   "passwd",
+		GroupOutput:    "group",
+		PasswdTemplate: template,
+	}
+
+	result, err := Do(cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	content, err := os.ReadFile(result.PasswdPath)
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+	if !strings.Contains(string(content), "root:x:0:0:root:/root:/bin/bash") {
+		t.Fatalf("expected existing root entry preserved, got: %s", content)
+	}
+	if !strings.Contains(string(content), result.PasswdLine) {
+		t.Fatalf("expected new entry appended, got: %s", content)
+	}
+
+	// Template itself must be untouched.
+	tmplContent, err := os.ReadFile(template)
+	if err != nil {
+		t.Fatalf("reading template: %v", err)
+	}
+	if string(tmplContent) != "root:x:0:0:root:/root:/bin/bash\n" {
+		t.Fatalf("template was mutated: %s", tmplContent)
+	}
+}
+
+func TestDoSkipsDuplicateUID(t *testing.T) {
+	workdir := t.TempDir()
+	template := filepath.Join(workdir, "passwd.tmpl")
+	if err := os.WriteFile(template, []byte("app:x:1001:1001:existing:/home/app:/bin/sh\n"), 0o644); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+
+	cfg := Config{
+		Username:       "app",
+		UID:            1001,
+		GID:            1001,
+		Home:           "/home/app",
+		Shell:          "/bin/sh",
+		Workdir:        workdir,
+		PasswdOutput:   "passwd",
+		GroupOutput:    "group",
+		PasswdTemplate: template,
+	}
+
+	result, err := Do(cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	content, err := os.ReadFile(result.PasswdPath)
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+	if strings.Count(string(content), "1001") != 2 {
+		t.Fatalf("expected existing uid 1001 entry left untouched and not duplicated, got: %s", content)
+	}
+	if !strings.Contains(string(content), "existing") {
+		t.Fatalf("expected existing entry to be preserved verbatim, got: %s", content)
+	}
+}
+
+func TestDoIsIdempotentAcrossReruns(t *testing.T) {
+	workdir := t.TempDir()
+	cfg := Config{
+		Username:     "app",
+		UID:          1001,
+		GID:          1001,
+		Home:         "/home/app",
+		Shell:        "/bin/sh",
+		Workdir:      workdir,
+		PasswdOutput: "passwd",
+		GroupOutput:  "group",
+	}
+
+	if _, err := Do(cfg); err != nil {
+		t.Fatalf("first Do failed: %v", err)
+	}
+	first, err := os.ReadFile(filepath.Join(workdir, "passwd"))
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+
+	// Re-running against the output it just wrote, as if it were now the
+	// template, must not duplicate the entry.
+	cfg.PasswdTemplate = filepath.Join(workdir, "passwd")
+	if _, err := Do(cfg); err != nil {
+		t.Fatalf("second Do failed: %v", err)
+	}
+	second, err := os.ReadFile(filepath.Join(workdir, "passwd"))
+	if err != nil {
+		t.Fatalf("reading passwd output: %v", err)
+	}
+
+	if string(first) != string(second) {
+		t.Fatalf("expected idempotent rerun, got %q then %q", first, second)
+	}
+}
+
+func TestDoWritesNsswitchConf(t *testing.T) {
+	workdir := t.TempDir()
+	cfg := Config{
+		Username:       "app",
+		UID:            1001,
+		GID:            1001,
+		Home:           "/home/app",
+		Shell:          "/bin/sh",
+		Workdir:        workdir,
+		PasswdOutput:   "passwd",
+		GroupOutput:    "group",
+		Nsswitch:       true,
+		NsswitchOutput: "nsswitch.conf",
+	}
+
+	result, err := Do(cfg)
+	if err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+	if result.NsswitchPath == "" {
+		t.Fatal("expected nsswitch path to be set")
+	}
+
+	content, err := os.ReadFile(result.NsswitchPath)
+	if err != nil {
+		t.Fatalf("reading nsswitch.conf: %v", err)
+	}
+	if !strings.Contains(string(content), "passwd: files") {
+		t.Fatalf("expected nsswitch.conf to prefer files, got: %s", content)
+	}
+}